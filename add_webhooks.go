@@ -1,10 +1,8 @@
 package gitlab
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
-	"strings"
 )
 
 // Webhook 增加webhooks请求数据结构
@@ -18,29 +16,22 @@ type Webhook struct {
 }
 
 // AddWebhooks 增加项目webhooks pushEventsURL, pipelineEventsURL,
-func (c *Client) AddWebhooks(projectID int, webhooks []Webhook) error {
-	api := fmt.Sprintf("%s/api/v4/projects/%v/hooks", c.BaseURL, projectID)
-
-	for _, webhook := range webhooks {
-		reqBody, err := json.Marshal(webhook)
-		if err != nil {
-			return err
-		}
+func (c *Client) AddWebhooks(projectID interface{}, webhooks []Webhook) error {
+	return c.AddWebhooksWithContext(context.Background(), projectID, webhooks)
+}
 
-		client := &http.Client{}
-		req, err := http.NewRequest("POST", api, strings.NewReader(string(reqBody)))
-		req.Header.Set("Private-Token", c.AccessToken)
-		req.Header.Set("Content-Type", "application/json")
+// AddWebhooksWithContext 增加项目webhooks, 请求绑定ctx
+func (c *Client) AddWebhooksWithContext(ctx context.Context, projectID interface{}, webhooks []Webhook) error {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return err
+	}
 
-		res, err := client.Do(req)
+	for _, webhook := range webhooks {
+		err := c.SendResourceWithContext(ctx, "POST", fmt.Sprintf("/projects/%s/hooks", pid), webhook, nil)
 		if err != nil {
 			return err
 		}
-		defer res.Body.Close()
-
-		if res.StatusCode < 200 || res.StatusCode > 299 {
-			return fmt.Errorf("request error response status code %v", res.StatusCode)
-		}
 	}
 
 	return nil
@@ -0,0 +1,183 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator 为每次请求设置认证信息, 重试时会再次调用
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// PrivateToken personal/project/group access token, 通过Private-Token请求头认证
+type PrivateToken string
+
+// Authenticate 设置Private-Token请求头
+func (t PrivateToken) Authenticate(req *http.Request) error {
+	req.Header.Set("Private-Token", string(t))
+	return nil
+}
+
+// JobToken CI job token(CI_JOB_TOKEN), 通过JOB-TOKEN请求头认证
+type JobToken string
+
+// Authenticate 设置JOB-TOKEN请求头
+func (t JobToken) Authenticate(req *http.Request) error {
+	req.Header.Set("JOB-TOKEN", string(t))
+	return nil
+}
+
+// OAuthRefreshFunc 获取新的OAuth2 access token及其过期时间, 过期时间为零值表示不过期
+type OAuthRefreshFunc func(ctx context.Context) (token string, expiry time.Time, err error)
+
+// OAuthToken OAuth2 access token, 通过Authorization: Bearer请求头认证.
+// 设置了refresh时, token过期或gitlab返回401后会调用refresh获取新token
+type OAuthToken struct {
+	mu      sync.Mutex
+	token   string
+	expiry  time.Time
+	refresh OAuthRefreshFunc
+}
+
+// NewOAuthToken 创建OAuth2认证, token可以为空, 此时第一次请求前调用refresh获取
+func NewOAuthToken(token string, refresh OAuthRefreshFunc) *OAuthToken {
+	return &OAuthToken{token: token, refresh: refresh}
+}
+
+// Authenticate 设置Authorization: Bearer请求头, 必要时刷新token
+func (t *OAuthToken) Authenticate(req *http.Request) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	expired := !t.expiry.IsZero() && time.Now().Add(10*time.Second).After(t.expiry)
+	if (t.token == "" || expired) && t.refresh != nil {
+		token, expiry, err := t.refresh(req.Context())
+		if err != nil {
+			return fmt.Errorf("gitlab: refresh oauth token: %w", err)
+		}
+		t.token, t.expiry = token, expiry
+	}
+	if t.token == "" {
+		return errors.New("gitlab: oauth token is empty")
+	}
+
+	req.Header.Set("Authorization", "Bearer "+t.token)
+	return nil
+}
+
+// invalidate 丢弃当前token, 下次请求时重新获取, 返回是否可以刷新
+func (t *OAuthToken) invalidate() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.refresh == nil {
+		return false
+	}
+	t.token = ""
+	return true
+}
+
+// WithAuth 使用自定义的认证方式, 代替AccessToken
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) error {
+		c.auth = auth
+		return nil
+	}
+}
+
+// WithJobToken 使用CI job token认证
+func WithJobToken(token string) Option {
+	return WithAuth(JobToken(token))
+}
+
+// WithOAuthToken 使用OAuth2 access token认证, refresh可以为nil
+func WithOAuthToken(token string, refresh OAuthRefreshFunc) Option {
+	return WithAuth(NewOAuthToken(token, refresh))
+}
+
+// WithPasswordAuth 使用用户名密码通过OAuth2 password grant获取access token, 仅用于旧脚本
+func WithPasswordAuth(username, password string) Option {
+	return func(c *Client) error {
+		c.auth = NewOAuthToken("", func(ctx context.Context) (string, time.Time, error) {
+			return c.passwordGrant(ctx, username, password)
+		})
+		return nil
+	}
+}
+
+// passwordGrant 调用/oauth/token获取access token, 请求不带认证信息, 与其他请求一样经过middleware、限流和重试,
+// 失败时返回*ErrorResponse
+func (c *Client) passwordGrant(ctx context.Context, username, password string) (string, time.Time, error) {
+	form := url.Values{
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+	}
+
+	ctx = withoutDryRun(withoutAuth(ctx))
+	req, err := c.newRequest(ctx, "POST", strings.TrimSuffix(c.BaseURL, "/")+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if _, err := c.Do(req, &token); err != nil {
+		return "", time.Time{}, err
+	}
+	if token.AccessToken == "" {
+		return "", time.Time{}, errors.New("gitlab: oauth password grant returned no access token")
+	}
+
+	var expiry time.Time
+	if token.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	return token.AccessToken, expiry, nil
+}
+
+type noAuthKey struct{}
+
+// withoutAuth 标记请求不需要认证, 如使用trigger token触发管道
+func withoutAuth(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noAuthKey{}, true)
+}
+
+// skipAuth 请求是否标记为不需要认证
+func skipAuth(ctx context.Context) bool {
+	skip, _ := ctx.Value(noAuthKey{}).(bool)
+	return skip
+}
+
+// authenticate 为请求设置认证信息, 未设置认证方式时使用AccessToken
+func (c *Client) authenticate(req *http.Request) error {
+	if skipAuth(req.Context()) {
+		return nil
+	}
+
+	if c.auth != nil {
+		return c.auth.Authenticate(req)
+	}
+	if c.AccessToken != "" {
+		return PrivateToken(c.AccessToken).Authenticate(req)
+	}
+
+	return nil
+}
+
+// reauthenticate gitlab返回401时判断是否可以刷新token后重试
+func (c *Client) reauthenticate() bool {
+	t, ok := c.auth.(*OAuthToken)
+	return ok && t.invalidate()
+}
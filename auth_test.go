@@ -0,0 +1,172 @@
+package gitlab_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/260by/gitlab"
+)
+
+func TestPasswordAuth(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/token" {
+			if r.Header.Get("Private-Token") != "" || r.Header.Get("Authorization") != "" {
+				t.Errorf("token request has auth header")
+			}
+			if r.FormValue("password") != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_grant","error_description":"The provided authorization grant is invalid."}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"oauth-token","expires_in":7200}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer oauth-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer srv.Close()
+
+	var logged []string
+	logRequests := gitlab.RequestMutator(func(req *http.Request) {
+		logged = append(logged, req.URL.Path)
+	})
+
+	plan := gitlab.NewDryRun(nil)
+	c, err := gitlab.NewClient(srv.URL, "", gitlab.WithPasswordAuth("root", "secret"), gitlab.WithMiddleware(logRequests), gitlab.WithDryRun(plan))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetProject(1); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(logged, ",") != "/oauth/token,/api/v4/projects/1" {
+		t.Errorf("requests through middleware = %v", logged)
+	}
+	if n := len(plan.Requests()); n != 0 {
+		t.Errorf("token request was planned by dry-run, got %d planned requests", n)
+	}
+
+	c, err = gitlab.NewClient(srv.URL, "", gitlab.WithPasswordAuth("root", "wrong"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = c.GetProject(1)
+	var e *gitlab.ErrorResponse
+	if !errors.As(err, &e) || e.StatusCode != http.StatusBadRequest || e.Err != "invalid_grant" {
+		t.Fatalf("error = %v, want 400 invalid_grant", err)
+	}
+	if !strings.Contains(err.Error(), "authorization grant is invalid") {
+		t.Errorf("error message = %v", err)
+	}
+}
+
+func TestOAuthRefreshOn401(t *testing.T) {
+	tests := []struct {
+		name     string
+		accepted string // 服务端接受的token, 空表示全部返回401
+		wantErr  bool
+	}{
+		{"refreshed token accepted", "new-token", false},
+		{"refreshed token rejected", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&requests, 1)
+				if tt.accepted == "" || r.Header.Get("Authorization") != "Bearer "+tt.accepted {
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte(`{"message":"401 Unauthorized"}`))
+					return
+				}
+				w.Write([]byte(`{"id":1}`))
+			}))
+			defer srv.Close()
+
+			var refreshes int32
+			refresh := func(ctx context.Context) (string, time.Time, error) {
+				atomic.AddInt32(&refreshes, 1)
+				return "new-token", time.Time{}, nil
+			}
+			c, err := gitlab.NewClient(srv.URL, "", gitlab.WithOAuthToken("old-token", refresh))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = c.GetProject(1)
+			if tt.wantErr {
+				if !gitlab.IsUnauthorized(err) {
+					t.Errorf("error = %v, want 401", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if n := atomic.LoadInt32(&refreshes); n != 1 {
+				t.Errorf("refresh called %d times, want 1", n)
+			}
+			if n := atomic.LoadInt32(&requests); n != 2 {
+				t.Errorf("got %d requests, want 2", n)
+			}
+		})
+	}
+}
+
+func TestOAuthTokenExpiry(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresIn time.Duration
+		want      int
+	}{
+		{"no expiry", 0, 1},
+		{"valid", time.Hour, 1},
+		{"expires within 10s", 5 * time.Second, 2},
+		{"expired", -time.Minute, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refreshes := 0
+			token := gitlab.NewOAuthToken("", func(ctx context.Context) (string, time.Time, error) {
+				refreshes++
+				var expiry time.Time
+				if tt.expiresIn != 0 {
+					expiry = time.Now().Add(tt.expiresIn)
+				}
+				return "token-" + strconv.Itoa(refreshes), expiry, nil
+			})
+
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest("GET", "/api/v4/projects", nil)
+				if err := token.Authenticate(req); err != nil {
+					t.Fatal(err)
+				}
+				if want := "Bearer token-" + strconv.Itoa(refreshes); req.Header.Get("Authorization") != want {
+					t.Errorf("Authorization = %q, want %q", req.Header.Get("Authorization"), want)
+				}
+			}
+			if refreshes != tt.want {
+				t.Errorf("refresh called %d times, want %d", refreshes, tt.want)
+			}
+		})
+	}
+
+	failing := gitlab.NewOAuthToken("", func(ctx context.Context) (string, time.Time, error) {
+		return "", time.Time{}, errors.New("boom")
+	})
+	if err := failing.Authenticate(httptest.NewRequest("GET", "/", nil)); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("refresh error = %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package gitlab
+
+import (
+	"container/list"
+	"net/http"
+	"sync"
+)
+
+// CacheEntry 缓存的GET响应
+type CacheEntry struct {
+	ETag   string
+	Header http.Header
+	Body   []byte
+}
+
+// Cache 响应缓存后端, 需要并发安全
+type Cache interface {
+	Get(key string) (CacheEntry, bool)
+	Set(key string, entry CacheEntry)
+}
+
+// WithCache 开启基于ETag的条件请求缓存: GET请求携带If-None-Match, gitlab返回304时使用缓存的响应体
+func WithCache(cache Cache) Option {
+	return func(c *Client) error {
+		c.cache = cache
+		return nil
+	}
+}
+
+// LRUCache 内存LRU缓存
+type LRUCache struct {
+	mu      sync.Mutex
+	size    int
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry CacheEntry
+}
+
+// NewLRUCache 创建最多保存size个响应的LRU缓存
+func NewLRUCache(size int) *LRUCache {
+	if size < 1 {
+		size = 1
+	}
+	return &LRUCache{
+		size:    size,
+		ll:      list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get 获取缓存
+func (l *LRUCache) Get(key string) (CacheEntry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+	l.ll.MoveToFront(e)
+
+	return e.Value.(*lruItem).entry, true
+}
+
+// Set 写入缓存, 超过容量时淘汰最久未使用的响应
+func (l *LRUCache) Set(key string, entry CacheEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e, ok := l.entries[key]; ok {
+		e.Value.(*lruItem).entry = entry
+		l.ll.MoveToFront(e)
+		return
+	}
+
+	l.entries[key] = l.ll.PushFront(&lruItem{key: key, entry: entry})
+	for l.ll.Len() > l.size {
+		oldest := l.ll.Back()
+		l.ll.Remove(oldest)
+		delete(l.entries, oldest.Value.(*lruItem).key)
+	}
+}
+
+// cacheKey 只缓存GET请求, 以sudo用户和完整地址作为key, 避免不同用户之间共享响应
+func (c *Client) cacheKey(req *http.Request) (string, bool) {
+	if c.cache == nil || req.Method != "GET" {
+		return "", false
+	}
+	return req.Header.Get("Sudo") + " " + req.URL.String(), true
+}
+
+// cacheRevalidate 存在缓存时设置If-None-Match
+func (c *Client) cacheRevalidate(req *http.Request) (CacheEntry, bool) {
+	key, ok := c.cacheKey(req)
+	if !ok {
+		return CacheEntry{}, false
+	}
+
+	entry, ok := c.cache.Get(key)
+	if !ok || entry.ETag == "" {
+		return CacheEntry{}, false
+	}
+	req.Header.Set("If-None-Match", entry.ETag)
+
+	return entry, true
+}
+
+// cacheStore 缓存带ETag的成功响应
+func (c *Client) cacheStore(req *http.Request, res *http.Response, body []byte) {
+	key, ok := c.cacheKey(req)
+	if !ok || res.StatusCode != http.StatusOK {
+		return
+	}
+
+	etag := res.Header.Get("ETag")
+	if etag == "" {
+		return
+	}
+
+	c.cache.Set(key, CacheEntry{
+		ETag:   etag,
+		Header: res.Header.Clone(),
+		Body:   append([]byte(nil), body...),
+	})
+}
+
+// cachedResponse 304时使用缓存的响应头和响应体, 新响应中的响应头优先
+func cachedResponse(res *http.Response, entry CacheEntry) []byte {
+	header := entry.Header.Clone()
+	for k, v := range res.Header {
+		header[k] = v
+	}
+	res.Header = header
+	res.StatusCode = http.StatusOK
+	res.Status = "200 OK"
+
+	return entry.Body
+}
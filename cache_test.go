@@ -0,0 +1,212 @@
+package gitlab_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/260by/gitlab"
+)
+
+// etagServer 按Sudo用户返回不同内容的ETag服务, 304响应不带分页响应头
+type etagServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	ifNoneMatch []string
+	notModified int
+}
+
+func newETagServer(t *testing.T) *etagServer {
+	s := &etagServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Header.Get("Sudo")
+		if user == "" {
+			user = "root"
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+		etag := fmt.Sprintf(`W/"%s-%s-%d"`, user, r.URL.Path, page)
+
+		s.mu.Lock()
+		s.ifNoneMatch = append(s.ifNoneMatch, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == etag {
+			s.notModified++
+			s.mu.Unlock()
+			w.Header().Set("ETag", etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/v4/projects" {
+			w.Header().Set("X-Page", strconv.Itoa(page))
+			w.Header().Set("X-Per-Page", "1")
+			w.Header().Set("X-Total", "2")
+			w.Header().Set("X-Total-Pages", "2")
+			next := ""
+			if page < 2 {
+				next = strconv.Itoa(page + 1)
+			}
+			w.Header().Set("X-Next-Page", next)
+			fmt.Fprintf(w, `[{"id":%d,"name":"%s-p%d"}]`, page, user, page)
+			return
+		}
+		fmt.Fprintf(w, `{"id":1,"name":"%s"}`, user)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// requests 返回并清空记录的If-None-Match请求头
+func (s *etagServer) requests() ([]string, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	h, n := s.ifNoneMatch, s.notModified
+	s.ifNoneMatch, s.notModified = nil, 0
+	return h, n
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	srv := newETagServer(t)
+	c, err := gitlab.NewClient(srv.URL, "token", gitlab.WithCache(gitlab.NewLRUCache(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		project, err := c.GetProject(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if project.Name != "root" {
+			t.Errorf("request %d: project name = %q, want root", i, project.Name)
+		}
+	}
+
+	sent, notModified := srv.requests()
+	if len(sent) != 2 || sent[0] != "" || sent[1] != `W/"root-/api/v4/projects/1-1"` {
+		t.Errorf("If-None-Match = %q", sent)
+	}
+	if notModified != 1 {
+		t.Errorf("got %d 304 responses, want 1", notModified)
+	}
+}
+
+func TestCachePaginationHeaders(t *testing.T) {
+	srv := newETagServer(t)
+	c, err := gitlab.NewClient(srv.URL, "token", gitlab.WithCache(gitlab.NewLRUCache(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := func() []gitlab.PageInfo {
+		var pages []gitlab.PageInfo
+		it := c.NewListIterator("/projects?per_page=1")
+		var page []gitlab.Project
+		for it.Next(context.Background(), &page) {
+			if len(page) != 1 || page[0].ID != it.PageInfo().Page {
+				t.Errorf("page %d = %+v", it.PageInfo().Page, page)
+			}
+			pages = append(pages, it.PageInfo())
+		}
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return pages
+	}
+
+	first := list()
+	srv.requests()
+	second := list()
+	if _, notModified := srv.requests(); notModified != 2 {
+		t.Errorf("got %d 304 responses, want 2", notModified)
+	}
+
+	if len(second) != 2 {
+		t.Fatalf("got %d pages from cache, want 2", len(second))
+	}
+	for i := range first {
+		if second[i].Page != first[i].Page || second[i].NextPage != first[i].NextPage ||
+			second[i].Total != 2 || second[i].TotalPages != 2 {
+			t.Errorf("page %d info = %+v, want %+v", i+1, second[i], first[i])
+		}
+	}
+}
+
+func TestCacheSudo(t *testing.T) {
+	srv := newETagServer(t)
+	c, err := gitlab.NewClient(srv.URL, "token", gitlab.WithCache(gitlab.NewLRUCache(10)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, user := range []string{"alice", "bob", "alice"} {
+		project, err := c.As(user).GetProject(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if project.Name != user {
+			t.Errorf("project name as %s = %q", user, project.Name)
+		}
+	}
+
+	sent, notModified := srv.requests()
+	want := []string{"", "", `W/"alice-/api/v4/projects/1-1"`}
+	if fmt.Sprint(sent) != fmt.Sprint(want) {
+		t.Errorf("If-None-Match = %q, want %q", sent, want)
+	}
+	if notModified != 1 {
+		t.Errorf("got %d 304 responses, want 1", notModified)
+	}
+}
+
+func TestLRUCacheEviction(t *testing.T) {
+	cache := gitlab.NewLRUCache(2)
+	cache.Set("a", gitlab.CacheEntry{ETag: "a"})
+	cache.Set("b", gitlab.CacheEntry{ETag: "b"})
+	cache.Get("a")
+	cache.Set("c", gitlab.CacheEntry{ETag: "c"})
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := cache.Get(key); ok != want {
+			t.Errorf("Get(%q) ok = %v, want %v", key, ok, want)
+		}
+	}
+
+	// 更新已有的key不淘汰其他缓存
+	cache.Set("a", gitlab.CacheEntry{ETag: "a2"})
+	if e, ok := cache.Get("a"); !ok || e.ETag != "a2" {
+		t.Errorf("Get(a) = %+v, %v", e, ok)
+	}
+	if _, ok := cache.Get("c"); !ok {
+		t.Error("updating a evicted c")
+	}
+}
+
+func TestCacheEvictionThroughClient(t *testing.T) {
+	srv := newETagServer(t)
+	c, err := gitlab.NewClient(srv.URL, "token", gitlab.WithCache(gitlab.NewLRUCache(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, user := range []string{"alice", "bob", "alice"} {
+		if _, err := c.As(user).GetProject(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 容量为1, bob的响应淘汰了alice的缓存
+	if _, notModified := srv.requests(); notModified != 0 {
+		t.Errorf("got %d 304 responses, want 0", notModified)
+	}
+}
@@ -0,0 +1,172 @@
+package gitlab
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option 创建Client时的可选配置, 按传入顺序依次生效
+type Option func(*Client) error
+
+// NewClient 创建gitlab api client
+func NewClient(baseURL, accessToken string, opts ...Option) (*Client, error) {
+	c := &Client{
+		BaseURL:     baseURL,
+		AccessToken: accessToken,
+		httpClient: &http.Client{
+			Transport: http.DefaultTransport.(*http.Transport).Clone(),
+		},
+		headers:    make(http.Header),
+		maxRetries: defaultMaxRetries,
+	}
+
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// WithHTTPClient 使用自定义的http.Client发送请求
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) error {
+		if httpClient == nil {
+			return errors.New("http client is nil")
+		}
+		hc := *httpClient
+		c.httpClient = &hc
+		return nil
+	}
+}
+
+// WithTransport 使用自定义的RoundTripper, 测试时可注入fake transport
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) error {
+		if rt == nil {
+			return errors.New("transport is nil")
+		}
+		c.httpClient.Transport = rt
+		return nil
+	}
+}
+
+// WithTimeout 设置单个请求的超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) error {
+		c.httpClient.Timeout = timeout
+		return nil
+	}
+}
+
+// WithProxy 通过指定代理访问gitlab, 如 http://proxy.example.com:3128
+func WithProxy(proxyURL string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return err
+		}
+
+		t, err := c.transport()
+		if err != nil {
+			return err
+		}
+		t.Proxy = http.ProxyURL(u)
+
+		return nil
+	}
+}
+
+// WithTLSConfig 使用自定义的TLS配置
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(c *Client) error {
+		t, err := c.transport()
+		if err != nil {
+			return err
+		}
+		t.TLSClientConfig = cfg
+
+		return nil
+	}
+}
+
+// WithCACert 信任PEM格式的CA证书, 用于自签名证书的gitlab
+func WithCACert(pem []byte) Option {
+	return func(c *Client) error {
+		t, err := c.transport()
+		if err != nil {
+			return err
+		}
+
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		if t.TLSClientConfig.RootCAs == nil {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			t.TLSClientConfig.RootCAs = pool
+		} else {
+			// tls.Config.Clone不会复制证书池, 复制后再追加以免修改调用方的证书池
+			t.TLSClientConfig.RootCAs = t.TLSClientConfig.RootCAs.Clone()
+		}
+		if !t.TLSClientConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return errors.New("no valid certificate found in CA bundle")
+		}
+
+		return nil
+	}
+}
+
+// WithCACertFile 从文件读取CA证书, 见WithCACert
+func WithCACertFile(path string) Option {
+	return func(c *Client) error {
+		pem, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return WithCACert(pem)(c)
+	}
+}
+
+// WithUserAgent 设置请求的User-Agent
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) error {
+		c.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithHeader 为每个请求增加默认header
+func WithHeader(key, value string) Option {
+	return func(c *Client) error {
+		c.headers.Add(key, value)
+		return nil
+	}
+}
+
+// transport 返回可修改的*http.Transport. 总是先复制一份再保存到httpClient,
+// 避免WithHTTPClient/WithTransport传入的transport(如http.DefaultTransport)被其他option修改
+func (c *Client) transport() (*http.Transport, error) {
+	if c.httpClient.Transport == nil {
+		c.httpClient.Transport = http.DefaultTransport
+	}
+
+	t, ok := c.httpClient.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("transport %T is not *http.Transport", c.httpClient.Transport)
+	}
+	t = t.Clone()
+	c.httpClient.Transport = t
+
+	return t, nil
+}
@@ -0,0 +1,47 @@
+package gitlab
+
+import (
+	"crypto/tls"
+	"net/http"
+	"testing"
+)
+
+func TestTransportOptionsDoNotModifySharedTransport(t *testing.T) {
+	def := http.DefaultTransport.(*http.Transport)
+	req, _ := http.NewRequest("GET", "https://gitlab.example.com/api/v4/projects", nil)
+
+	tests := []struct {
+		name string
+		base Option
+	}{
+		{"WithHTTPClient", WithHTTPClient(&http.Client{Transport: http.DefaultTransport})},
+		{"WithTransport", WithTransport(http.DefaultTransport)},
+		{"WithHTTPClient without transport", WithHTTPClient(&http.Client{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClient("https://gitlab.example.com", "token",
+				tt.base,
+				WithProxy("http://proxy.example.com:3128"),
+				WithTLSConfig(&tls.Config{ServerName: "gitlab.example.com"}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.httpClient.Transport == http.DefaultTransport {
+				t.Fatal("client still uses http.DefaultTransport")
+			}
+			if u, _ := def.Proxy(req); u != nil && u.Host == "proxy.example.com:3128" {
+				t.Error("proxy set on http.DefaultTransport")
+			}
+			if def.TLSClientConfig != nil && def.TLSClientConfig.ServerName == "gitlab.example.com" {
+				t.Error("tls config set on http.DefaultTransport")
+			}
+			if c.httpClient.Transport.(*http.Transport).Proxy == nil {
+				t.Error("proxy not set on client transport")
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// PlannedRequest dry-run模式下未发送的修改请求
+type PlannedRequest struct {
+	Method string
+	URL    string
+	Body   string // 请求体中的token、密码和地址中的账号密码会被隐藏, 见RedactBody
+}
+
+func (p PlannedRequest) String() string {
+	if p.Body == "" {
+		return p.Method + " " + p.URL
+	}
+	return p.Method + " " + p.URL + " " + p.Body
+}
+
+// DryRun 记录dry-run模式下的修改请求, 可在执行前审查
+type DryRun struct {
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	requests []PlannedRequest
+}
+
+// NewDryRun 创建dry-run计划, logger不为nil时每个修改请求都会记录一条日志
+func NewDryRun(logger *slog.Logger) *DryRun {
+	return &DryRun{logger: logger}
+}
+
+// WithDryRun 开启dry-run模式: POST/PUT/PATCH/DELETE请求不会发送, 只记录到d并返回占位结果,
+// 占位结果为请求体加上负数id, 如CreateProject返回的Project.ID为负数; GET请求正常发送
+func WithDryRun(d *DryRun) Option {
+	return func(c *Client) error {
+		c.dryRun = d
+		return nil
+	}
+}
+
+// Requests 返回已记录的修改请求
+func (d *DryRun) Requests() []PlannedRequest {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]PlannedRequest(nil), d.requests...)
+}
+
+// String 每行一个请求, 便于输出执行计划
+func (d *DryRun) String() string {
+	var b strings.Builder
+	for _, r := range d.Requests() {
+		b.WriteString(r.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+type noDryRunKey struct{}
+
+// withoutDryRun 标记请求在dry-run模式下照常发送, 如获取oauth token
+func withoutDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noDryRunKey{}, true)
+}
+
+// skipDryRun 请求是否标记为dry-run模式下照常发送
+func skipDryRun(ctx context.Context) bool {
+	skip, _ := ctx.Value(noDryRunKey{}).(bool)
+	return skip
+}
+
+// isMutating 是否为修改请求
+func isMutating(method string) bool {
+	switch method {
+	case "POST", "PUT", "PATCH", "DELETE":
+		return true
+	}
+	return false
+}
+
+// plan 记录请求并返回占位响应
+func (d *DryRun) plan(req *http.Request) (*http.Response, []byte, error) {
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		body, err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	d.mu.Lock()
+	planned := PlannedRequest{
+		Method: req.Method,
+		URL:    req.URL.String(),
+		Body:   string(RedactBody(req.Header.Get("Content-Type"), body)),
+	}
+	d.requests = append(d.requests, planned)
+	id := -len(d.requests)
+	d.mu.Unlock()
+
+	if d.logger != nil {
+		d.logger.LogAttrs(context.Background(), slog.LevelInfo, "gitlab dry-run",
+			slog.String("method", planned.Method),
+			slog.String("url", planned.URL),
+			slog.String("body", planned.Body),
+		)
+	}
+
+	status := http.StatusOK
+	if req.Method == "POST" {
+		status = http.StatusCreated
+	}
+	res := &http.Response{
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": {"application/json"}},
+		Body:       http.NoBody,
+		Request:    req,
+	}
+
+	if req.Method == "DELETE" {
+		return res, nil, nil
+	}
+	out, err := placeholder(req.Header.Get("Content-Type"), body, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return res, out, nil
+}
+
+// placeholder 以json请求体为基础生成占位结果, 并设置id. 请求体不是json对象时(如表单)只包含id
+func placeholder(contentType string, body []byte, id int) ([]byte, error) {
+	result := make(map[string]interface{})
+	if len(body) > 0 && strings.HasPrefix(contentType, "application/json") {
+		var v interface{}
+		if err := json.Unmarshal(body, &v); err != nil {
+			return nil, fmt.Errorf("gitlab: dry-run: decode request body: %v", err)
+		}
+		if obj, ok := v.(map[string]interface{}); ok {
+			result = obj
+		}
+	}
+	result["id"] = id
+
+	out, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("gitlab: dry-run: encode placeholder: %v", err)
+	}
+	return out, nil
+}
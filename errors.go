@@ -0,0 +1,129 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ErrorResponse gitlab返回非2xx状态码时的错误信息
+type ErrorResponse struct {
+	StatusCode int    // http状态码
+	Method     string // 请求方法
+	URL        string // 请求地址
+	Message    string // 响应体中的message字段, oauth接口为error_description字段
+	Err        string // 响应体中的error字段
+	RequestID  string // 响应头X-Request-Id
+	Body       []byte // 原始响应体
+}
+
+func (e *ErrorResponse) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Err
+	}
+	if msg == "" {
+		msg = strings.TrimSpace(string(e.Body))
+	}
+
+	s := fmt.Sprintf("%s %s: %d", e.Method, e.URL, e.StatusCode)
+	if msg != "" {
+		s += " " + msg
+	}
+	if e.RequestID != "" {
+		s += fmt.Sprintf(" (request id %s)", e.RequestID)
+	}
+
+	return s
+}
+
+// checkResponse 状态码非2xx时返回*ErrorResponse
+func checkResponse(res *http.Response, body []byte) error {
+	if res.StatusCode >= 200 && res.StatusCode <= 299 {
+		return nil
+	}
+
+	e := &ErrorResponse{
+		StatusCode: res.StatusCode,
+		RequestID:  res.Header.Get("X-Request-Id"),
+		Body:       body,
+	}
+	if res.Request != nil {
+		e.Method = res.Request.Method
+		e.URL = res.Request.URL.String()
+	}
+
+	var raw struct {
+		Message     interface{} `json:"message"`
+		Error       interface{} `json:"error"`
+		Description string      `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &raw); err == nil {
+		e.Message = flattenMessage(raw.Message)
+		e.Err = flattenMessage(raw.Error)
+		if e.Message == "" {
+			e.Message = raw.Description
+		}
+	}
+
+	return e
+}
+
+// flattenMessage gitlab的message可能是字符串、数组或字段校验错误的对象, 统一转为字符串
+func flattenMessage(v interface{}) string {
+	switch m := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return m
+	case []interface{}:
+		var parts []string
+		for _, item := range m {
+			parts = append(parts, flattenMessage(item))
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		var keys []string
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var parts []string
+		for _, k := range keys {
+			parts = append(parts, fmt.Sprintf("%s: %s", k, flattenMessage(m[k])))
+		}
+		return strings.Join(parts, "; ")
+	default:
+		return fmt.Sprint(m)
+	}
+}
+
+// hasStatus err是否为指定状态码的*ErrorResponse
+func hasStatus(err error, code int) bool {
+	var e *ErrorResponse
+	return errors.As(err, &e) && e.StatusCode == code
+}
+
+// IsNotFound 资源不存在(404)
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict 资源冲突(409), 如重复创建
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsUnauthorized token无效或缺失(401)
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsForbidden 没有权限(403)
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
@@ -1,157 +1,336 @@
 package gitlab
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
 	apiVersionPath = "/api/v4"
 )
 
-// Client gitlab api client
+// Client gitlab api client, 推荐使用NewClient创建.
+// Projects()等方法返回按资源划分的service, 消费方可以只依赖需要的service接口以便mock
 type Client struct {
 	BaseURL     string
 	AccessToken string
+
+	httpClient *http.Client
+	userAgent  string
+	headers    http.Header
+	auth       Authenticator
+	sudo       string
+
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	retryHook  RetryHook
+	limiter    *RateLimiter
+
+	middlewares []Middleware
+	cache       Cache
+	dryRun      *DryRun
+
+	pageWorkers int
 }
 
-// GetResource get gitlab resource detail
-func (c *Client) GetResource(api string, v interface{}) error {
-	httpClient := &http.Client{}
+// endpoint 拼接完整的api地址
+func (c *Client) endpoint(api string) string {
+	return fmt.Sprintf("%s%s%s", strings.TrimSuffix(c.BaseURL, "/"), apiVersionPath, api)
+}
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s%s%s", c.BaseURL, apiVersionPath, api), nil)
+// pathID 将仓库/组的数字ID或完整路径(如team/service-x)转为url路径段, 路径中的/编码为%2F
+func pathID(id interface{}) (string, error) {
+	switch v := id.(type) {
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case string:
+		if v == "" {
+			return "", errors.New("gitlab: empty project or group path")
+		}
+		return url.PathEscape(v), nil
+	}
+
+	return "", fmt.Errorf("gitlab: invalid ID type %T, want int or string path", id)
+}
+
+// newRequest 创建绑定ctx的请求
+func (c *Client) newRequest(ctx context.Context, method, rawURL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, rawURL, body)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range c.headers {
+		req.Header[k] = append([]string(nil), v...)
 	}
-	req.Header.Set("Private-Token", c.AccessToken)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	c.setSudo(req)
+
+	return req, nil
+}
 
-	res, err := httpClient.Do(req)
+// client 返回发送请求使用的http.Client, 直接构造的Client使用http.DefaultClient
+func (c *Client) client() *http.Client {
+	if c.httpClient == nil {
+		return http.DefaultClient
+	}
+	return c.httpClient
+}
+
+// do 发送请求, 返回响应以及读取后的响应体, 状态码非2xx时返回*ErrorResponse.
+// 429、5xx以及连接错误会按退避策略重试, 见shouldRetry
+func (c *Client) do(req *http.Request) (*http.Response, []byte, error) {
+	if c.dryRun != nil && isMutating(req.Method) && !skipDryRun(req.Context()) {
+		return c.dryRun.plan(req)
+	}
+
+	reauthenticated := false
+	for attempt := 1; ; attempt++ {
+		res, body, err := c.send(req)
+
+		// oauth token失效时刷新token后重试一次, 不计入重试次数. 不需要认证的请求(如获取token)不刷新
+		if res != nil && res.StatusCode == http.StatusUnauthorized && !reauthenticated && !skipAuth(req.Context()) && c.reauthenticate() {
+			reauthenticated = true
+			attempt--
+			if req.GetBody != nil {
+				if req.Body, err = req.GetBody(); err != nil {
+					return res, body, err
+				}
+			}
+			continue
+		}
+
+		if attempt > c.maxRetries || !shouldRetry(req, res, err) {
+			return res, body, err
+		}
+
+		wait := c.backoff(attempt, res)
+		if c.retryHook != nil {
+			c.retryHook(attempt, req, res, err, wait)
+		}
+
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return res, body, err
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return res, body, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// send 发送一次请求, 配置了限流器时先获取令牌, 配置了缓存时对GET请求使用ETag条件请求
+func (c *Client) send(req *http.Request) (*http.Response, []byte, error) {
+	if err := c.authenticate(req); err != nil {
+		return nil, nil, err
+	}
+
+	if c.limiter != nil {
+		if err := c.limiter.Wait(req.Context()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cached, revalidate := c.cacheRevalidate(req)
+
+	res, err := c.handler()(req)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer res.Body.Close()
 
+	if c.limiter != nil {
+		c.limiter.observe(res.Header)
+	}
+
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return err
+		return res, nil, err
 	}
 
-	if res.StatusCode < 200 || res.StatusCode > 299 {
-		return fmt.Errorf("request error response status code %v, response:%v", res.StatusCode, string(body))
+	if revalidate && res.StatusCode == http.StatusNotModified {
+		return res, cachedResponse(res, cached), nil
 	}
 
-	err = json.Unmarshal(body, &v)
+	if err := checkResponse(res, body); err != nil {
+		return res, body, err
+	}
+	c.cacheStore(req, res, body)
+
+	return res, body, nil
+}
+
+// GetResource get gitlab resource detail
+func (c *Client) GetResource(api string, v interface{}) error {
+	return c.GetResourceWithContext(context.Background(), api, v)
+}
+
+// GetResourceWithContext get gitlab resource detail, the request is bound to ctx
+func (c *Client) GetResourceWithContext(ctx context.Context, api string, v interface{}) error {
+	req, err := c.NewRequest(ctx, "GET", api, nil)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	_, err = c.Do(req, v)
+	return err
 }
 
 // GetResourceList get gitlab resource list
 func (c *Client) GetResourceList(api string, v interface{}) error {
-	var response string
-	page := 1
-	for {
-		u, err := url.Parse(fmt.Sprintf("%s%s%s", c.BaseURL, apiVersionPath, api))
-		if err != nil {
-			return err
-		}
-		q := u.Query()
-		q.Set("page", fmt.Sprint(page))
-		u.RawQuery = q.Encode()
-
-		httpClient := &http.Client{}
-
-		req, err := http.NewRequest("GET", u.String(), nil)
-		if err != nil {
-			return err
-		}
-
-		req.Header.Set("Private-Token", c.AccessToken)
+	return c.GetResourceListWithContext(context.Background(), api, v)
+}
 
-		res, err := httpClient.Do(req)
-		if err != nil {
-			return err
-		}
-		defer res.Body.Close()
+// GetResourceListWithContext get gitlab resource list, every page request is bound to ctx.
+// v must be a pointer to slice, each page is decoded separately and appended to it
+func (c *Client) GetResourceListWithContext(ctx context.Context, api string, v interface{}) error {
+	return collect(ctx, c.NewListIterator(api), v)
+}
 
-		body, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return err
-		}
+// GetResourceListKeysetWithContext 使用keyset分页获取全部列表数据, v必须是指向切片的指针
+func (c *Client) GetResourceListKeysetWithContext(ctx context.Context, api string, opt KeysetOptions, v interface{}) error {
+	return collect(ctx, c.NewKeysetIterator(api, opt), v)
+}
 
-		if res.StatusCode < 200 || res.StatusCode > 299 {
-			return fmt.Errorf("request error response status code %v, response:%s", res.StatusCode, string(body))
-		}
+// collect 遍历所有分页, 将每页数据追加到v
+func collect(ctx context.Context, it *ListIterator, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("gitlab: list result requires a pointer to slice, got %T", v)
+	}
 
-		response += string(body)
+	sliceType := rv.Elem().Type()
+	all := reflect.MakeSlice(sliceType, 0, 0)
 
-		s := res.Header.Get("X-Total-Pages")
-		if s == "" {
+	for {
+		page := reflect.New(sliceType)
+		if !it.Next(ctx, page.Interface()) {
 			break
 		}
-		totalPages, err := strconv.Atoi(s)
-		if err != nil {
-			return err
-		}
-
-		if page == totalPages {
+		all = reflect.AppendSlice(all, page.Elem())
+
+		if it.canFetchParallel() {
+			pages, err := it.fetchRemaining(ctx, sliceType)
+			if err != nil {
+				return err
+			}
+			for _, p := range pages {
+				all = reflect.AppendSlice(all, p)
+			}
 			break
 		}
-
-		page++
 	}
-
-	response = strings.ReplaceAll(response, "][", ",")
-
-	err := json.Unmarshal([]byte(response), &v)
-	if err != nil {
+	if err := it.Err(); err != nil {
 		return err
 	}
 
+	rv.Elem().Set(all)
+
 	return nil
 }
 
 // CreateResource 创建
 func (c *Client) CreateResource(api string, v interface{}) error {
-	client := http.Client{}
+	return c.CreateResourceWithContext(context.Background(), api, v)
+}
+
+// CreateResourceWithContext 创建, 请求绑定ctx
+func (c *Client) CreateResourceWithContext(ctx context.Context, api string, v interface{}) error {
+	return c.SendResourceWithContext(ctx, "POST", api, nil, v)
+}
 
-	u, err := url.Parse(fmt.Sprintf("%s%s%s", c.BaseURL, apiVersionPath, api))
+// SendResourceWithContext 发送POST/PUT/DELETE等请求, body不为nil时编码为json作为请求体,
+// 响应体不为空且v不为nil时解析到v
+func (c *Client) SendResourceWithContext(ctx context.Context, method, api string, body, v interface{}) error {
+	req, err := c.NewRequest(ctx, method, api, body)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", u.String(), nil)
-	if err != nil {
-		return err
+	_, err = c.Do(req, v)
+	return err
+}
+
+// UpdateResource 更新(PUT)
+func (c *Client) UpdateResource(api string, body, v interface{}) error {
+	return c.UpdateResourceWithContext(context.Background(), api, body, v)
+}
+
+// UpdateResourceWithContext 更新(PUT), 请求绑定ctx
+func (c *Client) UpdateResourceWithContext(ctx context.Context, api string, body, v interface{}) error {
+	return c.SendResourceWithContext(ctx, "PUT", api, body, v)
+}
+
+// DeleteResource 删除(DELETE)
+func (c *Client) DeleteResource(api string) error {
+	return c.DeleteResourceWithContext(context.Background(), api)
+}
+
+// DeleteResourceWithContext 删除(DELETE), 请求绑定ctx
+func (c *Client) DeleteResourceWithContext(ctx context.Context, api string) error {
+	return c.SendResourceWithContext(ctx, "DELETE", api, nil, nil)
+}
+
+// NewRequest 创建api请求, api为/api/v4之后的路径, body不为nil时编码为json作为请求体
+func (c *Client) NewRequest(ctx context.Context, method, api string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
 	}
-	req.Header.Set("Private-Token", c.AccessToken)
-	fmt.Println(u.String())
 
-	res, err := client.Do(req)
+	req, err := c.newRequest(ctx, method, c.endpoint(api), reader)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
-	defer res.Body.Close()
 
-	body, err := ioutil.ReadAll(res.Body)
+	return req, nil
+}
+
+// Do 发送请求, 响应体不为空时解析到v: v为io.Writer时写入原始响应体, 否则按json解析, v为nil时忽略.
+// 返回的*http.Response可用于读取响应头, 其Body已被读取并关闭
+func (c *Client) Do(req *http.Request, v interface{}) (*http.Response, error) {
+	res, body, err := c.do(req)
 	if err != nil {
-		return err
+		return res, err
 	}
 
-	if res.StatusCode < 200 || res.StatusCode > 299 {
-		return fmt.Errorf("request error response status code %v, response:%s", res.StatusCode, string(body))
+	if v == nil || len(bytes.TrimSpace(body)) == 0 {
+		return res, nil
 	}
 
-	if err = json.Unmarshal(body, &v); err != nil {
-		return err
+	if w, ok := v.(io.Writer); ok {
+		_, err = w.Write(body)
+		return res, err
 	}
 
-	return nil
+	return res, json.Unmarshal(body, v)
 }
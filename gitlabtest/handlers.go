@@ -0,0 +1,875 @@
+package gitlabtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/260by/gitlab"
+)
+
+// route 按路径段分发请求, 调用方需持有锁
+func (s *Server) route(w http.ResponseWriter, r *http.Request, seg []string) {
+	switch {
+	case match(seg, "projects"):
+		switch r.Method {
+		case "GET":
+			s.listProjects(w, r, s.sortedProjects(func(*gitlab.Project) bool { return true }))
+		case "POST":
+			s.createProject(w, r)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	case match(seg, "groups"):
+		switch r.Method {
+		case "GET":
+			s.listGroups(w, r, func(*gitlab.Group) bool { return true })
+		case "POST":
+			s.createGroup(w, r)
+		default:
+			methodNotAllowed(w)
+		}
+		return
+	case len(seg) >= 2 && seg[0] == "groups":
+		s.routeGroup(w, r, seg)
+		return
+	case len(seg) >= 2 && seg[0] == "projects":
+		s.routeProject(w, r, seg)
+		return
+	}
+
+	writeError(w, http.StatusNotFound, "404 Not Found")
+}
+
+// routeGroup 处理/groups/:id开头的请求
+func (s *Server) routeGroup(w http.ResponseWriter, r *http.Request, seg []string) {
+	g := s.group(seg[1])
+	if g == nil {
+		writeError(w, http.StatusNotFound, "404 Group Not Found")
+		return
+	}
+
+	switch {
+	case match(seg, "groups", "*") && r.Method == "GET":
+		writeJSON(w, http.StatusOK, g)
+	case match(seg, "groups", "*", "subgroups") && r.Method == "GET":
+		s.listGroups(w, r, func(sub *gitlab.Group) bool { return sub.ParentID == g.ID })
+	case match(seg, "groups", "*", "projects") && r.Method == "GET":
+		s.listProjects(w, r, s.sortedProjects(func(p *gitlab.Project) bool { return p.Namespace.ID == g.ID }))
+	default:
+		writeError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+// routeProject 处理/projects/:id开头的请求
+func (s *Server) routeProject(w http.ResponseWriter, r *http.Request, seg []string) {
+	p := s.project(seg[1])
+	if p == nil {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+
+	switch {
+	case match(seg, "projects", "*") && r.Method == "GET":
+		writeJSON(w, http.StatusOK, p)
+	case match(seg, "projects", "*") && r.Method == "PUT":
+		s.editProject(w, r, p)
+	case match(seg, "projects", "*") && r.Method == "DELETE":
+		delete(s.projects, p.ID)
+		writeJSON(w, http.StatusAccepted, map[string]string{"message": "202 Accepted"})
+	case match(seg, "projects", "*", "archive") && r.Method == "POST":
+		p.Archived = true
+		writeJSON(w, http.StatusCreated, p)
+	case match(seg, "projects", "*", "unarchive") && r.Method == "POST":
+		p.Archived = false
+		writeJSON(w, http.StatusCreated, p)
+	case match(seg, "projects", "*", "transfer") && r.Method == "PUT":
+		s.transferProject(w, r, p)
+	case match(seg, "projects", "*", "fork") && r.Method == "POST":
+		s.forkProject(w, r, p)
+	case match(seg, "projects", "*", "star") && r.Method == "POST":
+		if s.starred[p.ID] {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		s.starred[p.ID] = true
+		p.StarCount++
+		writeJSON(w, http.StatusCreated, p)
+	case match(seg, "projects", "*", "unstar") && r.Method == "POST":
+		if !s.starred[p.ID] {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		delete(s.starred, p.ID)
+		p.StarCount--
+		writeJSON(w, http.StatusCreated, p)
+
+	case match(seg, "projects", "*", "pipelines") && r.Method == "GET":
+		s.listPipelines(w, r, p.ID)
+	case match(seg, "projects", "*", "pipelines", "*") && r.Method == "GET":
+		if pipeline := s.pipeline(p.ID, seg[3]); pipeline != nil {
+			writeJSON(w, http.StatusOK, pipeline)
+			return
+		}
+		writeError(w, http.StatusNotFound, "404 Not found")
+	case match(seg, "projects", "*", "pipelines", "*", "variables") && r.Method == "GET":
+		pipeline := s.pipeline(p.ID, seg[3])
+		if pipeline == nil {
+			writeError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+		writeList(w, r, append([]gitlab.Variable{}, s.variables[pipeline.ID]...))
+	case match(seg, "projects", "*", "pipelines", "*", "jobs") && r.Method == "GET":
+		pipeline := s.pipeline(p.ID, seg[3])
+		if pipeline == nil {
+			writeError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+		s.listJobs(w, r, p.ID, pipeline.ID)
+
+	case match(seg, "projects", "*", "jobs") && r.Method == "GET":
+		s.listJobs(w, r, p.ID, 0)
+	case match(seg, "projects", "*", "jobs", "*") && r.Method == "GET":
+		if job := s.job(p.ID, seg[3]); job != nil {
+			writeJSON(w, http.StatusOK, job)
+			return
+		}
+		writeError(w, http.StatusNotFound, "404 Not found")
+	case match(seg, "projects", "*", "jobs", "*", "*") && r.Method == "POST":
+		s.actionJob(w, p.ID, seg[3], seg[4])
+
+	case match(seg, "projects", "*", "triggers") && r.Method == "GET":
+		list := []gitlab.Trigger{}
+		for _, t := range s.triggers[p.ID] {
+			list = append(list, *t)
+		}
+		writeList(w, r, list)
+	case match(seg, "projects", "*", "triggers") && r.Method == "POST":
+		s.createTrigger(w, r, p.ID)
+	case match(seg, "projects", "*", "trigger", "pipeline") && r.Method == "POST":
+		s.triggerPipeline(w, r, p.ID)
+
+	case match(seg, "projects", "*", "hooks") && r.Method == "GET":
+		writeList(w, r, append([]gitlab.Webhook{}, s.hooks[p.ID]...))
+	case match(seg, "projects", "*", "hooks") && r.Method == "POST":
+		var hook gitlab.Webhook
+		if !decode(w, r, &hook) {
+			return
+		}
+		hook.ID = p.ID
+		s.hooks[p.ID] = append(s.hooks[p.ID], hook)
+		writeJSON(w, http.StatusCreated, hook)
+
+	case match(seg, "projects", "*", "members") && r.Method == "GET":
+		s.listMembers(w, r, s.members[p.ID])
+	case match(seg, "projects", "*", "members", "all") && r.Method == "GET":
+		s.listMembers(w, r, s.allMembers(p))
+	case match(seg, "projects", "*", "members") && r.Method == "POST":
+		s.addMember(w, r, p.ID)
+	case match(seg, "projects", "*", "members", "*"):
+		s.member(w, r, p.ID, seg[3])
+	case match(seg, "projects", "*", "share") && r.Method == "POST":
+		s.shareProject(w, r, p.ID)
+	case match(seg, "projects", "*", "share", "*") && r.Method == "DELETE":
+		s.unshareProject(w, p.ID, seg[3])
+	case match(seg, "projects", "*", "invitations") && r.Method == "POST":
+		s.invite(w, r, p.ID)
+
+	case match(seg, "projects", "*", "repository", "tree") && r.Method == "GET":
+		ref := r.URL.Query().Get("ref")
+		if ref == "" {
+			ref = p.DefaultBranch
+		}
+		writeList(w, r, append([]gitlab.File{}, s.files[p.ID][ref]...))
+	case match(seg, "projects", "*", "repository", "commits") && r.Method == "POST":
+		s.createCommit(w, r, p.ID)
+
+	default:
+		writeError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+// match 路径段是否与pattern一致, *匹配任意一段
+func match(seg []string, pattern ...string) bool {
+	if len(seg) != len(pattern) {
+		return false
+	}
+	for i := range seg {
+		if pattern[i] != "*" && pattern[i] != seg[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func methodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+}
+
+// decode 解析json请求体, 失败时输出400
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad request - "+err.Error())
+		return false
+	}
+	return true
+}
+
+// project 按ID或完整路径查找仓库
+func (s *Server) project(ref string) *gitlab.Project {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return s.projects[id]
+	}
+	for _, p := range s.projects {
+		if p.PathWithNamespace == ref {
+			return p
+		}
+	}
+	return nil
+}
+
+// group 按ID或完整路径查找组
+func (s *Server) group(ref string) *gitlab.Group {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return s.groups[id]
+	}
+	for _, g := range s.groups {
+		if g.FullPath == ref {
+			return g
+		}
+	}
+	return nil
+}
+
+func (s *Server) pipeline(projectID int, ref string) *gitlab.Pipeline {
+	id, _ := strconv.Atoi(ref)
+	for _, p := range s.pipelines[projectID] {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Server) job(projectID int, ref string) *gitlab.Job {
+	id, _ := strconv.Atoi(ref)
+	for _, j := range s.jobs[projectID] {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+// sortedProjects 按ID排序返回满足条件的仓库
+func (s *Server) sortedProjects(keep func(*gitlab.Project) bool) []gitlab.Project {
+	list := []gitlab.Project{}
+	for _, p := range s.projects {
+		if keep(p) {
+			list = append(list, *p)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// listProjects 支持search和visibility过滤
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request, projects []gitlab.Project) {
+	q := r.URL.Query()
+	list := []gitlab.Project{}
+	for _, p := range projects {
+		if search := q.Get("search"); search != "" && !strings.Contains(p.Name, search) && !strings.Contains(p.Path, search) {
+			continue
+		}
+		if v := q.Get("visibility"); v != "" && p.Visibility != v {
+			continue
+		}
+		list = append(list, p)
+	}
+	writeList(w, r, list)
+}
+
+// listGroups 支持search过滤
+func (s *Server) listGroups(w http.ResponseWriter, r *http.Request, keep func(*gitlab.Group) bool) {
+	search := r.URL.Query().Get("search")
+	list := []gitlab.Group{}
+	for _, g := range s.groups {
+		if keep(g) && (search == "" || strings.Contains(g.Name, search) || strings.Contains(g.Path, search)) {
+			list = append(list, *g)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	writeList(w, r, list)
+}
+
+// listPipelines 支持status, ref, sha过滤, 默认按ID倒序
+func (s *Server) listPipelines(w http.ResponseWriter, r *http.Request, projectID int) {
+	q := r.URL.Query()
+	list := []gitlab.Pipeline{}
+	for _, p := range s.pipelines[projectID] {
+		if (q.Get("status") != "" && p.Status != q.Get("status")) ||
+			(q.Get("ref") != "" && p.Ref != q.Get("ref")) ||
+			(q.Get("sha") != "" && p.Sha != q.Get("sha")) {
+			continue
+		}
+		list = append(list, *p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if q.Get("sort") == "asc" {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].ID > list[j].ID
+	})
+	writeList(w, r, list)
+}
+
+// listJobs 支持scope[]过滤, pipelineID为0时返回仓库所有作业, 按ID倒序
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request, projectID, pipelineID int) {
+	scopes := r.URL.Query()["scope[]"]
+	list := []gitlab.Job{}
+	for _, j := range s.jobs[projectID] {
+		if pipelineID != 0 && j.Pipeline.ID != pipelineID {
+			continue
+		}
+		if len(scopes) > 0 && !contains(scopes, j.Status) {
+			continue
+		}
+		list = append(list, *j)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID > list[j].ID })
+	writeList(w, r, list)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// actionJob play或retry作业, retry会创建新的作业
+func (s *Server) actionJob(w http.ResponseWriter, projectID int, ref, action string) {
+	job := s.job(projectID, ref)
+	if job == nil {
+		writeError(w, http.StatusNotFound, "404 Not found")
+		return
+	}
+
+	switch action {
+	case "play":
+		job.Status = "pending"
+		writeJSON(w, http.StatusOK, job)
+	case "retry":
+		retried := *job
+		retried.ID = s.id()
+		retried.Status = "pending"
+		s.jobs[projectID] = append(s.jobs[projectID], &retried)
+		writeJSON(w, http.StatusCreated, retried)
+	case "cancel":
+		job.Status = "canceled"
+		writeJSON(w, http.StatusCreated, job)
+	default:
+		writeError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	var opt gitlab.CreateProjectOptions
+	if !decode(w, r, &opt) {
+		return
+	}
+	if opt.Name == "" && opt.Path == "" {
+		writeError(w, http.StatusBadRequest, "name or path is missing")
+		return
+	}
+
+	p := gitlab.Project{
+		Name:          opt.Name,
+		Path:          opt.Path,
+		Description:   opt.Description,
+		Visibility:    opt.Visibility,
+		DefaultBranch: opt.DefaultBranch,
+		Topics:        opt.Topics,
+		MergeMethod:   opt.MergeMethod,
+		CIConfigPath:  opt.CIConfigPath,
+	}
+	if p.Name == "" {
+		p.Name = p.Path
+	}
+	if p.Path == "" {
+		p.Path = p.Name
+	}
+	p.Namespace.ID = opt.NamespaceID
+
+	for _, existing := range s.projects {
+		if existing.Namespace.ID == opt.NamespaceID && existing.Path == p.Path {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+				"message": map[string][]string{"path": {"has already been taken"}},
+			})
+			return
+		}
+	}
+
+	p = s.addProject(p)
+	if opt.InitializeWithReadme {
+		s.files[p.ID] = map[string][]gitlab.File{
+			p.DefaultBranch: {{Name: "README.md", Path: "README.md", Type: "blob"}},
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, p)
+}
+
+func (s *Server) createGroup(w http.ResponseWriter, r *http.Request) {
+	var opt gitlab.CreateGroupOptions
+	if !decode(w, r, &opt) {
+		return
+	}
+	if opt.Name == "" || opt.Path == "" {
+		writeError(w, http.StatusBadRequest, "name and path are required")
+		return
+	}
+	if opt.ParentID != 0 && s.groups[opt.ParentID] == nil {
+		writeError(w, http.StatusNotFound, "404 Group Not Found")
+		return
+	}
+	for _, existing := range s.groups {
+		if existing.ParentID == opt.ParentID && existing.Path == opt.Path {
+			writeError(w, http.StatusBadRequest, "Failed to save group {:path=>[\"has already been taken\"]}")
+			return
+		}
+	}
+
+	g := s.addGroup(gitlab.Group{
+		Name:        opt.Name,
+		Path:        opt.Path,
+		ParentID:    opt.ParentID,
+		Description: opt.Description,
+		Visibility:  opt.Visibility,
+	})
+
+	writeJSON(w, http.StatusCreated, g)
+}
+
+func (s *Server) createTrigger(w http.ResponseWriter, r *http.Request, projectID int) {
+	var opt gitlab.CreateTriggerOptions
+	if !decode(w, r, &opt) {
+		return
+	}
+
+	t := gitlab.Trigger{
+		ID:          s.id(),
+		Description: opt.Description,
+	}
+	t.Token = fmt.Sprintf("glptt-%040d", t.ID)
+	s.triggers[projectID] = append(s.triggers[projectID], &t)
+
+	writeJSON(w, http.StatusCreated, t)
+}
+
+// triggerPipeline 校验trigger token并创建pending状态的管道
+func (s *Server) triggerPipeline(w http.ResponseWriter, r *http.Request, projectID int) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token := r.PostForm.Get("token")
+	valid := false
+	for _, t := range s.triggers[projectID] {
+		if t.Token == token {
+			valid = true
+		}
+	}
+	if !valid && (s.token == "" || token != s.token) {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+
+	variables := make(map[string]string)
+	for k, v := range r.PostForm {
+		if strings.HasPrefix(k, "variables[") && strings.HasSuffix(k, "]") {
+			variables[k[len("variables["):len(k)-1]] = v[0]
+		}
+	}
+
+	ref := r.PostForm.Get("ref")
+	s.triggered = append(s.triggered, TriggeredPipeline{
+		ProjectID: projectID,
+		Token:     token,
+		Ref:       ref,
+		Variables: variables,
+	})
+
+	p := &gitlab.Pipeline{ID: s.id(), Status: "pending", Ref: ref}
+	s.pipelines[projectID] = append(s.pipelines[projectID], p)
+
+	writeJSON(w, http.StatusCreated, p)
+}
+
+// createCommit 记录提交内容, create动作会把根目录下的文件加入仓库文件列表
+func (s *Server) createCommit(w http.ResponseWriter, r *http.Request, projectID int) {
+	var opt gitlab.CreateFileOptions
+	if !decode(w, r, &opt) {
+		return
+	}
+	if opt.Branch == "" || opt.CommitMessage == "" {
+		writeError(w, http.StatusBadRequest, "branch, commit_message are missing")
+		return
+	}
+	s.commits[projectID] = append(s.commits[projectID], opt)
+
+	if s.files[projectID] == nil {
+		s.files[projectID] = make(map[string][]gitlab.File)
+	}
+	for _, a := range opt.Actions {
+		if a.Action == "create" && !strings.Contains(a.FilePath, "/") {
+			s.files[projectID][opt.Branch] = append(s.files[projectID][opt.Branch], gitlab.File{
+				Name: a.FilePath,
+				Path: a.FilePath,
+				Type: "blob",
+			})
+		}
+	}
+
+	id := fmt.Sprintf("%040x", s.id())
+	writeJSON(w, http.StatusCreated, map[string]string{
+		"id":       id,
+		"short_id": id[:8],
+		"title":    strings.SplitN(opt.CommitMessage, "\n", 2)[0],
+		"message":  opt.CommitMessage,
+	})
+}
+
+// editProject 修改仓库的基本设置, 功能开关只校验不保存
+func (s *Server) editProject(w http.ResponseWriter, r *http.Request, p *gitlab.Project) {
+	var opt gitlab.EditProjectOptions
+	if !decode(w, r, &opt) {
+		return
+	}
+
+	if opt.Name != nil {
+		p.Name = *opt.Name
+	}
+	if opt.Path != nil {
+		p.Path = *opt.Path
+		p.PathWithNamespace = p.Path
+		if p.Namespace.FullPath != "" {
+			p.PathWithNamespace = p.Namespace.FullPath + "/" + p.Path
+		}
+	}
+	if opt.Description != nil {
+		p.Description = *opt.Description
+	}
+	if opt.Visibility != nil {
+		p.Visibility = *opt.Visibility
+	}
+	if opt.DefaultBranch != nil {
+		p.DefaultBranch = *opt.DefaultBranch
+	}
+	if opt.MergeMethod != nil {
+		p.MergeMethod = *opt.MergeMethod
+	}
+	if opt.CIConfigPath != nil {
+		p.CIConfigPath = *opt.CIConfigPath
+	}
+	if opt.Topics != nil {
+		p.Topics = *opt.Topics
+	}
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+// transferProject 按ID或完整路径查找目标组并移动仓库
+func (s *Server) transferProject(w http.ResponseWriter, r *http.Request, p *gitlab.Project) {
+	var opt struct {
+		Namespace interface{} `json:"namespace"`
+	}
+	if !decode(w, r, &opt) {
+		return
+	}
+
+	var g *gitlab.Group
+	switch v := opt.Namespace.(type) {
+	case float64:
+		g = s.groups[int(v)]
+	case string:
+		g = s.group(v)
+	}
+	if g == nil {
+		writeError(w, http.StatusNotFound, "404 Namespace Not Found")
+		return
+	}
+	for _, existing := range s.projects {
+		if existing.Namespace.ID == g.ID && existing.Path == p.Path {
+			writeError(w, http.StatusBadRequest, "Project with same name or path in target namespace already exists")
+			return
+		}
+	}
+
+	moved := *p
+	moved.Namespace.ID = g.ID
+	moved.PathWithNamespace = ""
+
+	writeJSON(w, http.StatusOK, s.addProject(moved))
+}
+
+// forkProject 复制仓库到指定的组, 未指定组时fork到顶级命名空间
+func (s *Server) forkProject(w http.ResponseWriter, r *http.Request, p *gitlab.Project) {
+	var opt gitlab.ForkProjectOptions
+	if !decode(w, r, &opt) {
+		return
+	}
+
+	namespaceID := opt.NamespaceID
+	if opt.NamespacePath != "" {
+		g := s.group(opt.NamespacePath)
+		if g == nil {
+			writeError(w, http.StatusNotFound, "404 Namespace Not Found")
+			return
+		}
+		namespaceID = g.ID
+	}
+	if namespaceID != 0 && s.groups[namespaceID] == nil {
+		writeError(w, http.StatusNotFound, "404 Namespace Not Found")
+		return
+	}
+
+	parent := *p
+	parent.ForkedFromProject = nil
+	fork := gitlab.Project{
+		Name:              p.Name,
+		Path:              p.Path,
+		Description:       p.Description,
+		Visibility:        p.Visibility,
+		DefaultBranch:     p.DefaultBranch,
+		ForkedFromProject: &parent,
+	}
+	if opt.Name != "" {
+		fork.Name = opt.Name
+	}
+	if opt.Path != "" {
+		fork.Path = opt.Path
+	}
+	if opt.Description != "" {
+		fork.Description = opt.Description
+	}
+	if opt.Visibility != "" {
+		fork.Visibility = opt.Visibility
+	}
+	fork.Namespace.ID = namespaceID
+
+	for _, existing := range s.projects {
+		if existing.Namespace.ID == namespaceID && existing.Path == fork.Path {
+			writeJSON(w, http.StatusConflict, map[string]interface{}{
+				"message": map[string][]string{"path": {"has already been taken"}},
+			})
+			return
+		}
+	}
+
+	p.ForksCount++
+	writeJSON(w, http.StatusCreated, s.addProject(fork))
+}
+
+// newMember 补全成员的用户名和状态
+func newMember(id int, m gitlab.ProjectMember) gitlab.ProjectMember {
+	m.ID = id
+	if m.Username == "" {
+		m.Username = fmt.Sprintf("user%d", m.ID)
+	}
+	if m.Name == "" {
+		m.Name = m.Username
+	}
+	if m.State == "" {
+		m.State = "active"
+	}
+	return m
+}
+
+// allMembers 合并仓库直接成员和上级组成员, 同一用户取最高权限
+func (s *Server) allMembers(p *gitlab.Project) []*gitlab.ProjectMember {
+	byID := make(map[int]*gitlab.ProjectMember)
+	merge := func(members []*gitlab.ProjectMember) {
+		for _, m := range members {
+			if existing, ok := byID[m.ID]; !ok || existing.AccessLevel < m.AccessLevel {
+				byID[m.ID] = m
+			}
+		}
+	}
+
+	merge(s.members[p.ID])
+	for g := s.groups[p.Namespace.ID]; g != nil; g = s.groups[g.ParentID] {
+		merge(s.gmembers[g.ID])
+	}
+
+	list := make([]*gitlab.ProjectMember, 0, len(byID))
+	for _, m := range byID {
+		list = append(list, m)
+	}
+	return list
+}
+
+// listMembers 支持query过滤, 按ID排序
+func (s *Server) listMembers(w http.ResponseWriter, r *http.Request, members []*gitlab.ProjectMember) {
+	query := r.URL.Query().Get("query")
+	list := []gitlab.ProjectMember{}
+	for _, m := range members {
+		if query == "" || strings.Contains(m.Username, query) || strings.Contains(m.Name, query) {
+			list = append(list, *m)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	writeList(w, r, list)
+}
+
+func (s *Server) addMember(w http.ResponseWriter, r *http.Request, projectID int) {
+	var opt gitlab.AddProjectMemberOptions
+	if !decode(w, r, &opt) {
+		return
+	}
+	if opt.UserID == 0 && opt.Username == "" {
+		writeError(w, http.StatusBadRequest, "400 Bad request - user_id, username are missing, exactly one parameter must be provided")
+		return
+	}
+	if opt.AccessLevel == gitlab.NoAccess {
+		writeError(w, http.StatusBadRequest, "400 Bad request - access_level is missing")
+		return
+	}
+	for _, m := range s.members[projectID] {
+		if m.ID == opt.UserID || (opt.Username != "" && m.Username == opt.Username) {
+			writeError(w, http.StatusConflict, "Member already exists")
+			return
+		}
+	}
+
+	id := opt.UserID
+	if id == 0 {
+		id = s.id()
+	}
+	m := newMember(id, gitlab.ProjectMember{Username: opt.Username, AccessLevel: opt.AccessLevel})
+	if opt.ExpiresAt != nil {
+		t := opt.ExpiresAt.Time()
+		m.ExpiresAt = &t
+	}
+	s.members[projectID] = append(s.members[projectID], &m)
+
+	writeJSON(w, http.StatusCreated, m)
+}
+
+// member 获取、修改或移除直接成员
+func (s *Server) member(w http.ResponseWriter, r *http.Request, projectID int, ref string) {
+	id, _ := strconv.Atoi(ref)
+	index := -1
+	for i, m := range s.members[projectID] {
+		if m.ID == id {
+			index = i
+		}
+	}
+	if index < 0 {
+		writeError(w, http.StatusNotFound, "404 Member Not Found")
+		return
+	}
+	m := s.members[projectID][index]
+
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, m)
+	case "PUT":
+		var opt gitlab.EditProjectMemberOptions
+		if !decode(w, r, &opt) {
+			return
+		}
+		m.AccessLevel = opt.AccessLevel
+		if opt.ExpiresAt != nil {
+			t := opt.ExpiresAt.Time()
+			m.ExpiresAt = &t
+		}
+		writeJSON(w, http.StatusOK, m)
+	case "DELETE":
+		s.members[projectID] = append(s.members[projectID][:index], s.members[projectID][index+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		methodNotAllowed(w)
+	}
+}
+
+func (s *Server) shareProject(w http.ResponseWriter, r *http.Request, projectID int) {
+	var opt gitlab.ShareProjectOptions
+	if !decode(w, r, &opt) {
+		return
+	}
+	if s.groups[opt.GroupID] == nil {
+		writeError(w, http.StatusNotFound, "404 Group Not Found")
+		return
+	}
+	for _, share := range s.shares[projectID] {
+		if share.GroupID == opt.GroupID {
+			writeError(w, http.StatusConflict, "409 Conflict: Project is already shared with this group")
+			return
+		}
+	}
+	s.shares[projectID] = append(s.shares[projectID], opt)
+
+	writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"id":           s.id(),
+		"project_id":   projectID,
+		"group_id":     opt.GroupID,
+		"group_access": opt.GroupAccess,
+		"expires_at":   opt.ExpiresAt,
+	})
+}
+
+func (s *Server) unshareProject(w http.ResponseWriter, projectID int, ref string) {
+	groupID, _ := strconv.Atoi(ref)
+	for i, share := range s.shares[projectID] {
+		if share.GroupID == groupID {
+			s.shares[projectID] = append(s.shares[projectID][:i], s.shares[projectID][i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 Not Found")
+}
+
+// invite 记录邀请, 与gitlab一致邀请失败时仍返回201, 在响应体中说明失败的邮箱
+func (s *Server) invite(w http.ResponseWriter, r *http.Request, projectID int) {
+	var opt gitlab.InviteProjectMembersOptions
+	if !decode(w, r, &opt) {
+		return
+	}
+	if opt.Email == "" {
+		writeError(w, http.StatusBadRequest, "400 Bad request - email is missing")
+		return
+	}
+
+	failed := make(map[string]string)
+	for _, email := range strings.Split(opt.Email, ",") {
+		email = strings.TrimSpace(email)
+		for _, invited := range s.invited[projectID] {
+			if invited.Email == email {
+				failed[email] = "The member's email address has already been taken"
+			}
+		}
+		if _, ok := failed[email]; ok {
+			continue
+		}
+		s.invited[projectID] = append(s.invited[projectID], gitlab.InviteProjectMembersOptions{
+			Email:       email,
+			AccessLevel: opt.AccessLevel,
+			ExpiresAt:   opt.ExpiresAt,
+		})
+	}
+
+	if len(failed) > 0 {
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"status": "error", "message": failed})
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"status": "success"})
+}
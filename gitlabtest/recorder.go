@@ -0,0 +1,250 @@
+package gitlabtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/260by/gitlab"
+)
+
+// Mode 录制回放模式
+type Mode int
+
+const (
+	// ModeReplay 只从golden文件回放, 没有匹配的记录时返回错误
+	ModeReplay Mode = iota
+	// ModeRecord 发送真实请求并记录, 调用Save写入golden文件
+	ModeRecord
+	// ModeAuto golden文件存在时回放, 否则录制
+	ModeAuto
+)
+
+// Scrubbed 替换token等敏感信息的占位符
+const Scrubbed = gitlab.Redacted
+
+// RecordedRequest 记录的请求, 不保存请求头
+type RecordedRequest struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"` // 按key排序后的查询参数
+	Body   string `json:"body,omitempty"`
+}
+
+// RecordedResponse 记录的响应
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Interaction 一次请求和响应
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Recorder 录制/回放gitlab请求的http.RoundTripper, 按方法、路径和排序后的查询参数(包括page)匹配.
+// 录制时会去掉认证请求头, 并替换请求和响应中的trigger token、runners_token等字段, 见gitlab.RedactBody
+//
+//	rec, err := gitlabtest.NewRecorder("testdata/projects.json", gitlabtest.ModeAuto, nil)
+//	c, err := gitlab.NewClient(baseURL, token, gitlab.WithTransport(rec))
+//	...
+//	err = rec.Save()
+type Recorder struct {
+	path string
+	mode Mode
+	base http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// NewRecorder 创建录制回放transport, base为录制时使用的transport, nil时使用http.DefaultTransport
+func NewRecorder(path string, mode Mode, base http.RoundTripper) (*Recorder, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	r := &Recorder{path: path, mode: mode, base: base}
+
+	if mode == ModeAuto {
+		r.mode = ModeRecord
+		if _, err := os.Stat(path); err == nil {
+			r.mode = ModeReplay
+		}
+	}
+
+	if r.mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.interactions); err != nil {
+			return nil, fmt.Errorf("gitlabtest: parse %s: %v", path, err)
+		}
+		r.used = make([]bool, len(r.interactions))
+	}
+
+	return r, nil
+}
+
+// Recording 是否处于录制模式
+func (r *Recorder) Recording() bool {
+	return r.mode == ModeRecord
+}
+
+// RoundTrip 录制模式下发送请求并记录, 回放模式下返回匹配的记录
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, out, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Path:   req.URL.EscapedPath(),
+		Query:  normalizeQuery(req.URL.Query()),
+		Body:   string(gitlab.RedactBody(req.Header.Get("Content-Type"), body)),
+	}
+
+	if r.mode == ModeReplay {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return r.replay(req, recorded)
+	}
+
+	res, err := r.base.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, &Interaction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     scrubHeader(res.Header),
+			Body:       string(gitlab.RedactBody("application/json", resBody)),
+		},
+	})
+	r.mu.Unlock()
+
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+	res.Request = req
+	return res, nil
+}
+
+// replay 返回第一条未使用的匹配记录
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.interactions {
+		if r.used[i] || !matches(in.Request, recorded) {
+			continue
+		}
+		r.used[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("gitlabtest: no recorded interaction for %s %s?%s", recorded.Method, recorded.Path, recorded.Query)
+}
+
+// matches 按方法、路径、查询参数匹配, 请求体只在记录中存在时比较
+func matches(a, b RecordedRequest) bool {
+	return a.Method == b.Method && a.Path == b.Path && a.Query == b.Query &&
+		(a.Body == "" || a.Body == b.Body)
+}
+
+// Save 录制模式下将记录写入golden文件, 回放模式下不做任何事
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, append(data, '\n'), 0o644)
+}
+
+// readBody 读取请求体, 不修改req. 设置了GetBody时读取其返回的副本, out为req;
+// 否则读取并关闭req.Body, out为带有新请求体的req副本, 用于继续发送
+func readBody(req *http.Request) (body []byte, out *http.Request, err error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, req, nil
+	}
+
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer rc.Close()
+
+		body, err = io.ReadAll(rc)
+		if err != nil {
+			return nil, nil, err
+		}
+		return body, req, nil
+	}
+
+	body, err = io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	out = req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, out, nil
+}
+
+// normalizeQuery 去掉认证参数后按key排序编码
+func normalizeQuery(q url.Values) string {
+	for _, key := range []string{"private_token", "access_token", "job_token"} {
+		q.Del(key)
+	}
+	return q.Encode()
+}
+
+// scrubHeader 隐藏认证信息, 去掉cookie、Date等不需要保存的响应头
+func scrubHeader(h http.Header) http.Header {
+	out := gitlab.RedactHeaders(h)
+	for _, key := range []string{"Set-Cookie", "Date"} {
+		out.Del(key)
+	}
+	return out
+}
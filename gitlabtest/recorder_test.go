@@ -0,0 +1,182 @@
+package gitlabtest_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/260by/gitlab"
+	"github.com/260by/gitlab/gitlabtest"
+)
+
+// onceReader 只能读取一次的请求体, 没有GetBody
+type onceReader struct {
+	io.Reader
+	closed bool
+}
+
+func (r *onceReader) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestRecorderKeepsRequest(t *testing.T) {
+	srv := gitlabtest.NewServer()
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "golden.json")
+	rec, err := gitlabtest.NewRecorder(path, gitlabtest.ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		getBody bool
+	}{
+		{"without GetBody", false},
+		{"with GetBody", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := `{"name":"demo","token":"secret"}`
+			body := &onceReader{Reader: strings.NewReader(payload)}
+			req, err := http.NewRequest("POST", srv.URL+"/api/v4/projects", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Body = body
+			if tt.getBody {
+				req.GetBody = func() (io.ReadCloser, error) {
+					return io.NopCloser(strings.NewReader(payload)), nil
+				}
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			res, err := rec.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("status = %d", res.StatusCode)
+			}
+
+			if req.Body != body {
+				t.Error("RoundTrip replaced the request body")
+			}
+			if !body.closed {
+				t.Error("RoundTrip did not close the request body")
+			}
+			if res.Request != req {
+				t.Error("response does not reference the original request")
+			}
+		})
+	}
+
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := gitlabtest.NewRecorder(path, gitlabtest.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := &onceReader{Reader: bytes.NewReader([]byte(`{"name":"demo","token":"other"}`))}
+	req, err := http.NewRequest("POST", srv.URL+"/api/v4/projects", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Body = body
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := replay.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		t.Errorf("replayed status = %d, want 201", res.StatusCode)
+	}
+	if req.Body != body || !body.closed {
+		t.Error("replay did not leave the request body in place and closed")
+	}
+}
+
+func TestRecorderGoldenFile(t *testing.T) {
+	srv := gitlabtest.NewServer()
+	defer srv.Close()
+	srv.RequireToken("client-token")
+	p := srv.AddProject(gitlab.Project{Name: "demo"})
+	srv.AddProject(gitlab.Project{Name: "other"})
+	trigger := srv.AddTrigger(p.ID, gitlab.Trigger{Token: "trigger-token"})
+
+	path := filepath.Join(t.TempDir(), "golden.json")
+	rec, err := gitlabtest.NewRecorder(path, gitlabtest.ModeRecord, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := gitlab.NewClient(srv.URL, "client-token", gitlab.WithTransport(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.GetTrigger(p.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.TriggerPipeline(p.ID, trigger.Token, map[string]string{"ENV": "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	recorded := listPages(t, c)
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"client-token", "trigger-token"} {
+		if bytes.Contains(data, []byte(secret)) {
+			t.Errorf("golden file contains %q:\n%s", secret, data)
+		}
+	}
+
+	// 回放时按page查询参数匹配每一页, 不需要服务端和token
+	replay, err := gitlabtest.NewRecorder(path, gitlabtest.ModeReplay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = gitlab.NewClient("http://gitlab.invalid", "", gitlab.WithTransport(replay))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed := listPages(t, c); strings.Join(replayed, ",") != strings.Join(recorded, ",") {
+		t.Errorf("replayed pages = %v, want %v", replayed, recorded)
+	}
+}
+
+// listPages 每页一个仓库, 返回各页的页码和仓库名
+func listPages(t *testing.T, c *gitlab.Client) []string {
+	t.Helper()
+
+	var pages []string
+	it := c.NewListIterator("/projects?per_page=1")
+	var page []gitlab.Project
+	for it.Next(context.Background(), &page) {
+		for _, p := range page {
+			pages = append(pages, fmt.Sprintf("%d:%s", it.PageInfo().Page, p.Name))
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return pages
+}
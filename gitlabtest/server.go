@@ -0,0 +1,533 @@
+// Package gitlabtest 提供基于httptest.Server的内存gitlab服务, 用于测试依赖gitlab.Client的代码.
+//
+//	srv := gitlabtest.NewServer()
+//	defer srv.Close()
+//
+//	project := srv.AddProject(gitlab.Project{Name: "demo"})
+//	srv.AddPipeline(project.ID, gitlab.Pipeline{Status: "success", Ref: "master"})
+//	srv.FailNext("GET", "/projects/*/pipelines", http.StatusBadGateway, 1)
+//
+//	c, _ := srv.Client()
+//	pipelines, err := c.ListPipelines(project.ID)
+package gitlabtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/260by/gitlab"
+)
+
+const apiVersionPath = "/api/v4"
+
+// TriggeredPipeline 通过trigger接口触发的管道请求
+type TriggeredPipeline struct {
+	ProjectID int
+	Token     string
+	Ref       string
+	Variables map[string]string
+}
+
+// Server 内存gitlab服务, 所有方法并发安全
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	token     string
+	nextID    int
+	projects  map[int]*gitlab.Project
+	groups    map[int]*gitlab.Group
+	pipelines map[int][]*gitlab.Pipeline
+	variables map[int][]gitlab.Variable // key为pipeline ID
+	jobs      map[int][]*gitlab.Job
+	triggers  map[int][]*gitlab.Trigger
+	hooks     map[int][]gitlab.Webhook
+	files     map[int]map[string][]gitlab.File // project ID -> ref -> 根目录文件
+	commits   map[int][]gitlab.CreateFileOptions
+	starred   map[int]bool
+	members   map[int][]*gitlab.ProjectMember // project ID -> 直接成员
+	gmembers  map[int][]*gitlab.ProjectMember // group ID -> 组成员
+	shares    map[int][]gitlab.ShareProjectOptions
+	invited   map[int][]gitlab.InviteProjectMembersOptions
+	triggered []TriggeredPipeline
+	failures  []*failure
+}
+
+// failure 注入的错误响应
+type failure struct {
+	method  string
+	pattern string
+	query   url.Values
+	status  int
+	times   int
+}
+
+// NewServer 创建并启动服务, 使用完需要调用Close
+func NewServer() *Server {
+	s := &Server{
+		nextID:    1,
+		projects:  make(map[int]*gitlab.Project),
+		groups:    make(map[int]*gitlab.Group),
+		pipelines: make(map[int][]*gitlab.Pipeline),
+		variables: make(map[int][]gitlab.Variable),
+		jobs:      make(map[int][]*gitlab.Job),
+		triggers:  make(map[int][]*gitlab.Trigger),
+		hooks:     make(map[int][]gitlab.Webhook),
+		files:     make(map[int]map[string][]gitlab.File),
+		commits:   make(map[int][]gitlab.CreateFileOptions),
+		starred:   make(map[int]bool),
+		members:   make(map[int][]*gitlab.ProjectMember),
+		gmembers:  make(map[int][]*gitlab.ProjectMember),
+		shares:    make(map[int][]gitlab.ShareProjectOptions),
+		invited:   make(map[int][]gitlab.InviteProjectMembersOptions),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Client 创建访问该服务的gitlab.Client, 默认不重试以便测试错误注入
+func (s *Server) Client(opts ...gitlab.Option) (*gitlab.Client, error) {
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+
+	opts = append([]gitlab.Option{gitlab.WithMaxRetries(0)}, opts...)
+	return gitlab.NewClient(s.URL, token, opts...)
+}
+
+// RequireToken 要求请求携带指定的Private-Token、JOB-TOKEN或Bearer token, 否则返回401
+func (s *Server) RequireToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = token
+}
+
+// FailNext 使接下来times个匹配method和pattern的请求返回status, times小于0表示一直失败.
+// pattern为/api/v4之后的路径, 支持path.Match通配符, 如 /projects/*/jobs.
+// pattern可以带查询参数, 此时请求必须包含这些参数且值相同, 如 /projects?page=3
+func (s *Server) FailNext(method, pattern string, status, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := &failure{method: method, pattern: pattern, status: status, times: times}
+	if i := strings.Index(pattern, "?"); i >= 0 {
+		f.pattern = pattern[:i]
+		f.query, _ = url.ParseQuery(pattern[i+1:])
+	}
+	s.failures = append(s.failures, f)
+}
+
+// id 分配新的资源ID, 调用方需持有锁
+func (s *Server) id() int {
+	id := s.nextID
+	s.nextID++
+	return id
+}
+
+// reserve 预留显式指定的ID, 调用方需持有锁
+func (s *Server) reserve(id int) int {
+	if id == 0 {
+		return s.id()
+	}
+	if id >= s.nextID {
+		s.nextID = id + 1
+	}
+	return id
+}
+
+// AddGroup 增加组, ID为0时自动分配
+func (s *Server) AddGroup(g gitlab.Group) gitlab.Group {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addGroup(g)
+}
+
+func (s *Server) addGroup(g gitlab.Group) gitlab.Group {
+	g.ID = s.reserve(g.ID)
+	if g.Path == "" {
+		g.Path = g.Name
+	}
+	if g.FullPath == "" {
+		g.FullPath = g.Path
+		if parent, ok := s.groups[g.ParentID]; ok {
+			g.FullPath = parent.FullPath + "/" + g.Path
+		}
+	}
+	if g.Visibility == "" {
+		g.Visibility = "private"
+	}
+	g.WebURL = s.URL + "/groups/" + g.FullPath
+	s.groups[g.ID] = &g
+
+	return g
+}
+
+// AddProject 增加仓库, ID为0时自动分配, Namespace.ID指向已存在的组时补全命名空间信息
+func (s *Server) AddProject(p gitlab.Project) gitlab.Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addProject(p)
+}
+
+func (s *Server) addProject(p gitlab.Project) gitlab.Project {
+	p.ID = s.reserve(p.ID)
+	if p.Path == "" {
+		p.Path = p.Name
+	}
+	if p.Visibility == "" {
+		p.Visibility = "private"
+	}
+	if p.DefaultBranch == "" {
+		p.DefaultBranch = "master"
+	}
+	if g, ok := s.groups[p.Namespace.ID]; ok {
+		p.Namespace.Name = g.Name
+		p.Namespace.Path = g.Path
+		p.Namespace.FullPath = g.FullPath
+		p.Namespace.Kind = "group"
+	}
+	if p.PathWithNamespace == "" {
+		p.PathWithNamespace = p.Path
+		if p.Namespace.FullPath != "" {
+			p.PathWithNamespace = p.Namespace.FullPath + "/" + p.Path
+		}
+	}
+	if p.NameWithNamespace == "" {
+		p.NameWithNamespace = p.Name
+	}
+	p.WebURL = s.URL + "/" + p.PathWithNamespace
+	p.HTTPURLToRepo = p.WebURL + ".git"
+	s.projects[p.ID] = &p
+
+	return p
+}
+
+// AddPipeline 增加管道及其变量, ID为0时自动分配
+func (s *Server) AddPipeline(projectID int, p gitlab.Pipeline, variables ...gitlab.Variable) gitlab.Pipeline {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p.ID = s.reserve(p.ID)
+	s.pipelines[projectID] = append(s.pipelines[projectID], &p)
+	s.variables[p.ID] = append(s.variables[p.ID], variables...)
+
+	return p
+}
+
+// AddJob 增加作业, Pipeline.ID决定作业属于哪个管道, ID为0时自动分配
+func (s *Server) AddJob(projectID int, j gitlab.Job) gitlab.Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j.ID = s.reserve(j.ID)
+	s.jobs[projectID] = append(s.jobs[projectID], &j)
+
+	return j
+}
+
+// AddTrigger 增加触发器, ID为0时自动分配
+func (s *Server) AddTrigger(projectID int, t gitlab.Trigger) gitlab.Trigger {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t.ID = s.reserve(t.ID)
+	s.triggers[projectID] = append(s.triggers[projectID], &t)
+
+	return t
+}
+
+// AddFile 增加仓库根目录下的文件或目录
+func (s *Server) AddFile(projectID int, ref string, f gitlab.File) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.files[projectID] == nil {
+		s.files[projectID] = make(map[string][]gitlab.File)
+	}
+	if f.Path == "" {
+		f.Path = f.Name
+	}
+	if f.Type == "" {
+		f.Type = "blob"
+	}
+	s.files[projectID][ref] = append(s.files[projectID][ref], f)
+}
+
+// AddProjectMember 增加仓库的直接成员, ID为0时自动分配
+func (s *Server) AddProjectMember(projectID int, m gitlab.ProjectMember) gitlab.ProjectMember {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m = newMember(s.reserve(m.ID), m)
+	s.members[projectID] = append(s.members[projectID], &m)
+
+	return m
+}
+
+// AddGroupMember 增加组成员, 组及其子组下的仓库通过/members/all可以查到该成员
+func (s *Server) AddGroupMember(groupID int, m gitlab.ProjectMember) gitlab.ProjectMember {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m = newMember(s.reserve(m.ID), m)
+	s.gmembers[groupID] = append(s.gmembers[groupID], &m)
+
+	return m
+}
+
+// Hooks 返回仓库已添加的webhooks
+func (s *Server) Hooks(projectID int) []gitlab.Webhook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]gitlab.Webhook(nil), s.hooks[projectID]...)
+}
+
+// Commits 返回通过commits接口提交的内容
+func (s *Server) Commits(projectID int) []gitlab.CreateFileOptions {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]gitlab.CreateFileOptions(nil), s.commits[projectID]...)
+}
+
+// Shares 返回仓库当前共享的组
+func (s *Server) Shares(projectID int) []gitlab.ShareProjectOptions {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]gitlab.ShareProjectOptions(nil), s.shares[projectID]...)
+}
+
+// Invitations 返回通过invitations接口发送的邀请
+func (s *Server) Invitations(projectID int) []gitlab.InviteProjectMembersOptions {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]gitlab.InviteProjectMembersOptions(nil), s.invited[projectID]...)
+}
+
+// TriggeredPipelines 返回通过trigger接口触发的管道
+func (s *Server) TriggeredPipelines() []TriggeredPipeline {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]TriggeredPipeline(nil), s.triggered...)
+}
+
+// serveHTTP 处理错误注入和认证后分发到对应的handler
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, apiVersionPath+"/") {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	route := strings.TrimPrefix(r.URL.EscapedPath(), apiVersionPath)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if status, ok := s.injectedFailure(r.Method, route, r.URL.Query()); ok {
+		writeError(w, status, http.StatusText(status))
+		return
+	}
+
+	if s.token != "" && !isTriggerRoute(route) && !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+
+	var segments []string
+	for _, seg := range strings.Split(strings.Trim(route, "/"), "/") {
+		seg, err := url.PathUnescape(seg)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		segments = append(segments, seg)
+	}
+
+	s.route(w, r, segments)
+}
+
+// injectedFailure 查找匹配的注入错误, 调用方需持有锁
+func (s *Server) injectedFailure(method, route string, query url.Values) (int, bool) {
+	for i, f := range s.failures {
+		if f.method != method {
+			continue
+		}
+		if ok, _ := path.Match(f.pattern, route); !ok || !matchQuery(f.query, query) {
+			continue
+		}
+
+		if f.times > 0 {
+			f.times--
+			if f.times == 0 {
+				s.failures = append(s.failures[:i], s.failures[i+1:]...)
+			}
+		}
+		return f.status, true
+	}
+
+	return 0, false
+}
+
+// matchQuery want中的每个参数在query中都有相同的值
+func matchQuery(want, query url.Values) bool {
+	for key := range want {
+		if query.Get(key) != want.Get(key) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	return r.Header.Get("Private-Token") == s.token ||
+		r.Header.Get("JOB-TOKEN") == s.token ||
+		r.Header.Get("Authorization") == "Bearer "+s.token
+}
+
+func isTriggerRoute(route string) bool {
+	ok, _ := path.Match("/projects/*/trigger/pipeline", route)
+	return ok
+}
+
+// writeJSON 输出json响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 按gitlab格式输出错误
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+// writeList 按page/per_page或keyset参数分页输出items(切片), 并设置gitlab的分页响应头
+func writeList(w http.ResponseWriter, r *http.Request, items interface{}) {
+	v := reflect.ValueOf(items)
+	q := r.URL.Query()
+
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+	if perPage <= 0 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+
+	if q.Get("pagination") == "keyset" {
+		writeKeysetList(w, r, v, perPage)
+		return
+	}
+
+	total := v.Len()
+	totalPages := (total + perPage - 1) / perPage
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	page, _ := strconv.Atoi(q.Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	start, end := (page-1)*perPage, page*perPage
+	if start > total {
+		start = total
+	}
+	if end > total {
+		end = total
+	}
+
+	h := w.Header()
+	h.Set("X-Page", strconv.Itoa(page))
+	h.Set("X-Per-Page", strconv.Itoa(perPage))
+	h.Set("X-Total", strconv.Itoa(total))
+	h.Set("X-Total-Pages", strconv.Itoa(totalPages))
+	h.Set("X-Next-Page", "")
+	h.Set("X-Prev-Page", "")
+
+	var links []string
+	if page < totalPages {
+		h.Set("X-Next-Page", strconv.Itoa(page+1))
+		links = append(links, pageLink(r, "page", strconv.Itoa(page+1), "next"))
+	}
+	if page > 1 {
+		h.Set("X-Prev-Page", strconv.Itoa(page-1))
+		links = append(links, pageLink(r, "page", strconv.Itoa(page-1), "prev"))
+	}
+	links = append(links, pageLink(r, "page", "1", "first"), pageLink(r, "page", strconv.Itoa(totalPages), "last"))
+	h.Set("Link", strings.Join(links, ", "))
+
+	writeJSON(w, http.StatusOK, v.Slice(start, end).Interface())
+}
+
+// writeKeysetList keyset分页, 按ID排序, 通过id_after/id_before游标和Link响应头翻页
+func writeKeysetList(w http.ResponseWriter, r *http.Request, v reflect.Value, perPage int) {
+	q := r.URL.Query()
+	desc := q.Get("sort") == "desc"
+
+	ids := make([]int, v.Len())
+	for i := range ids {
+		ids[i] = int(reflect.Indirect(v.Index(i)).FieldByName("ID").Int())
+	}
+	order := make([]int, v.Len())
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool {
+		if desc {
+			return ids[order[a]] > ids[order[b]]
+		}
+		return ids[order[a]] < ids[order[b]]
+	})
+
+	cursorKey := "id_after"
+	if desc {
+		cursorKey = "id_before"
+	}
+	cursor, hasCursor := 0, false
+	if c := q.Get(cursorKey); c != "" {
+		cursor, _ = strconv.Atoi(c)
+		hasCursor = true
+	}
+
+	out := reflect.MakeSlice(v.Type(), 0, perPage)
+	more := false
+	for _, i := range order {
+		if hasCursor && ((desc && ids[i] >= cursor) || (!desc && ids[i] <= cursor)) {
+			continue
+		}
+		if out.Len() == perPage {
+			more = true
+			break
+		}
+		out = reflect.Append(out, v.Index(i))
+	}
+
+	if more {
+		last := int(reflect.Indirect(out.Index(out.Len() - 1)).FieldByName("ID").Int())
+		w.Header().Set("Link", pageLink(r, cursorKey, strconv.Itoa(last), "next"))
+	}
+
+	writeJSON(w, http.StatusOK, out.Interface())
+}
+
+// pageLink 生成Link响应头中的一项
+func pageLink(r *http.Request, key, value, rel string) string {
+	u := *r.URL
+	u.Scheme = "http"
+	u.Host = r.Host
+	q := u.Query()
+	q.Set(key, value)
+	u.RawQuery = q.Encode()
+
+	return "<" + u.String() + `>; rel="` + rel + `"`
+}
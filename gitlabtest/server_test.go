@@ -0,0 +1,342 @@
+package gitlabtest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/260by/gitlab"
+	"github.com/260by/gitlab/gitlabtest"
+)
+
+// newClient 创建服务和访问它的Client, 测试结束时关闭服务
+func newClient(t *testing.T, opts ...gitlab.Option) (*gitlabtest.Server, *gitlab.Client) {
+	t.Helper()
+
+	srv := gitlabtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	c, err := srv.Client(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, c
+}
+
+// addProject 在组下增加仓库
+func addProject(srv *gitlabtest.Server, name string, groupID int) gitlab.Project {
+	p := gitlab.Project{Name: name}
+	p.Namespace.ID = groupID
+	return srv.AddProject(p)
+}
+
+func TestListPagination(t *testing.T) {
+	srv, c := newClient(t)
+	for i := 0; i < 45; i++ {
+		srv.AddProject(gitlab.Project{Name: fmt.Sprintf("p%02d", i)})
+	}
+
+	it := c.NewListIterator("/projects?per_page=20")
+	var pages []gitlab.PageInfo
+	var all []gitlab.Project
+	var page []gitlab.Project
+	for it.Next(context.Background(), &page) {
+		pages = append(pages, it.PageInfo())
+		all = append(all, page...)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(all) != 45 {
+		t.Fatalf("got %d projects, want 45", len(all))
+	}
+	for i, p := range all {
+		if want := fmt.Sprintf("p%02d", i); p.Name != want {
+			t.Errorf("project %d = %s, want %s", i, p.Name, want)
+		}
+	}
+
+	if len(pages) != 3 {
+		t.Fatalf("got %d pages, want 3", len(pages))
+	}
+	first, last := pages[0], pages[2]
+	if first.Page != 1 || first.NextPage != 2 || first.Total != 45 || first.TotalPages != 3 || first.PerPage != 20 {
+		t.Errorf("first page info = %+v", first)
+	}
+	if _, ok := first.Links["next"]; !ok {
+		t.Error("first page has no rel=next link")
+	}
+	if last.Page != 3 || last.NextPage != 0 || last.PrevPage != 2 {
+		t.Errorf("last page info = %+v", last)
+	}
+	if _, ok := last.Links["next"]; ok {
+		t.Error("last page has a rel=next link")
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	srv, c := newClient(t)
+	srv.AddProject(gitlab.Project{Name: "api", Visibility: "public"})
+	srv.AddProject(gitlab.Project{Name: "web"})
+
+	projects, err := c.ListProjectsWithContext(context.Background(), &gitlab.ListProjectsOptions{Visibility: "public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || projects[0].Name != "api" {
+		t.Errorf("public projects = %+v", projects)
+	}
+
+	projects, err = c.ListProjectsWithContext(context.Background(), &gitlab.ListProjectsOptions{Search: "we"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || projects[0].Name != "web" {
+		t.Errorf("search projects = %+v", projects)
+	}
+}
+
+func TestKeysetPagination(t *testing.T) {
+	srv, c := newClient(t)
+	p := srv.AddProject(gitlab.Project{Name: "demo"})
+	for i := 0; i < 25; i++ {
+		srv.AddProject(gitlab.Project{Name: fmt.Sprintf("p%02d", i)})
+		srv.AddJob(p.ID, gitlab.Job{Name: fmt.Sprintf("job%02d", i)})
+	}
+
+	projects, err := c.ListProjectsKeyset(gitlab.KeysetOptions{PerPage: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 26 {
+		t.Fatalf("got %d projects, want 26", len(projects))
+	}
+	for i := 1; i < len(projects); i++ {
+		if projects[i].ID <= projects[i-1].ID {
+			t.Fatalf("projects not in ascending id order at %d", i)
+		}
+	}
+
+	jobs, err := c.ListProjectJobsKeyset(p.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 25 {
+		t.Fatalf("got %d jobs, want 25", len(jobs))
+	}
+	for i := 1; i < len(jobs); i++ {
+		if jobs[i].ID >= jobs[i-1].ID {
+			t.Fatalf("jobs not in descending id order at %d", i)
+		}
+	}
+}
+
+func TestLookupByPath(t *testing.T) {
+	srv, c := newClient(t)
+	team := srv.AddGroup(gitlab.Group{Name: "team"})
+	sub := srv.AddGroup(gitlab.Group{Name: "sub", ParentID: team.ID})
+	p := addProject(srv, "service-x", sub.ID)
+	srv.AddTrigger(p.ID, gitlab.Trigger{Token: "trigger-token"})
+
+	project, err := c.GetProject("team/sub/service-x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.ID != p.ID || project.PathWithNamespace != "team/sub/service-x" {
+		t.Errorf("project = %d %s", project.ID, project.PathWithNamespace)
+	}
+
+	group, err := c.GetGroup("team/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.ID != sub.ID {
+		t.Errorf("group id = %d, want %d", group.ID, sub.ID)
+	}
+
+	projects, err := c.ListGroupsProjects("team/sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || projects[0].ID != p.ID {
+		t.Errorf("group projects = %+v", projects)
+	}
+
+	token, err := c.GetTrigger("team/sub/service-x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "trigger-token" {
+		t.Errorf("trigger token = %q", token)
+	}
+
+	if _, err := c.GetProject("team/service-x"); !gitlab.IsNotFound(err) {
+		t.Errorf("unknown path error = %v, want 404", err)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	srv, c := newClient(t)
+
+	group, err := c.CreateGroup(&gitlab.CreateGroupOptions{Name: "team", Path: "team"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := c.CreateSubGroup("sub", group.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sub.FullPath != "team/sub" {
+		t.Errorf("sub group full path = %q", sub.FullPath)
+	}
+
+	project, err := c.CreateProjectWithOptions(&gitlab.CreateProjectOptions{
+		Name:                 "svc",
+		NamespaceID:          sub.ID,
+		DefaultBranch:        "main",
+		InitializeWithReadme: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.PathWithNamespace != "team/sub/svc" || project.DefaultBranch != "main" {
+		t.Errorf("project = %s %s", project.PathWithNamespace, project.DefaultBranch)
+	}
+
+	_, err = c.CreateProject("svc", sub.ID)
+	var e *gitlab.ErrorResponse
+	if !errors.As(err, &e) || e.StatusCode != http.StatusBadRequest {
+		t.Errorf("duplicate project error = %v, want 400", err)
+	}
+
+	err = c.CreateFile(project.ID, "main", "add ci", map[string]string{".gitlab-ci.yml": "stages: [build]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := c.CheckCIFile(project.ID, "main"); err != nil || !ok {
+		t.Errorf("CheckCIFile = %v, %v", ok, err)
+	}
+	if commits := srv.Commits(project.ID); len(commits) != 1 || commits[0].CommitMessage != "add ci" {
+		t.Errorf("commits = %+v", commits)
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []gitlab.Option
+		times   int
+		wantErr bool
+	}{
+		{"no retry", nil, 1, true},
+		{"retried", []gitlab.Option{gitlab.WithMaxRetries(2), gitlab.WithRetryBackoff(time.Millisecond, time.Millisecond)}, 2, false},
+		{"retries exhausted", []gitlab.Option{gitlab.WithMaxRetries(2), gitlab.WithRetryBackoff(time.Millisecond, time.Millisecond)}, -1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, c := newClient(t, tt.opts...)
+			p := srv.AddProject(gitlab.Project{Name: "demo"})
+			srv.AddPipeline(p.ID, gitlab.Pipeline{Status: "success"})
+			srv.FailNext("GET", "/projects/*/pipelines", http.StatusBadGateway, tt.times)
+
+			pipelines, err := c.ListPipelines(p.ID)
+			if tt.wantErr {
+				var e *gitlab.ErrorResponse
+				if !errors.As(err, &e) || e.StatusCode != http.StatusBadGateway {
+					t.Fatalf("error = %v, want 502", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pipelines) != 1 {
+				t.Errorf("got %d pipelines, want 1", len(pipelines))
+			}
+		})
+	}
+}
+
+func TestFailNextQuery(t *testing.T) {
+	srv, c := newClient(t)
+	for i := 0; i < 3; i++ {
+		srv.AddProject(gitlab.Project{Name: fmt.Sprintf("p%d", i)})
+	}
+	srv.FailNext("GET", "/projects?page=2", http.StatusInternalServerError, 1)
+
+	it := c.NewListIterator("/projects?per_page=1")
+	var page []gitlab.Project
+	n := 0
+	for it.Next(context.Background(), &page) {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("got %d pages before failure, want 1", n)
+	}
+	if e := (*gitlab.ErrorResponse)(nil); !errors.As(it.Err(), &e) || e.StatusCode != http.StatusInternalServerError {
+		t.Errorf("error = %v, want 500", it.Err())
+	}
+
+	// 错误只注入一次
+	projects, err := c.ListProjects()
+	if err != nil || len(projects) != 3 {
+		t.Errorf("ListProjects = %d, %v", len(projects), err)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	srv := gitlabtest.NewServer()
+	defer srv.Close()
+	srv.RequireToken("secret")
+	p := srv.AddProject(gitlab.Project{Name: "demo"})
+	trigger := srv.AddTrigger(p.ID, gitlab.Trigger{Token: "trigger-token"})
+
+	tests := []struct {
+		name   string
+		client func() (*gitlab.Client, error)
+		ok     bool
+	}{
+		{"server client", func() (*gitlab.Client, error) { return srv.Client() }, true},
+		{"wrong token", func() (*gitlab.Client, error) { return gitlab.NewClient(srv.URL, "wrong", gitlab.WithMaxRetries(0)) }, false},
+		{"no token", func() (*gitlab.Client, error) { return gitlab.NewClient(srv.URL, "", gitlab.WithMaxRetries(0)) }, false},
+		{"job token", func() (*gitlab.Client, error) { return gitlab.NewClient(srv.URL, "", gitlab.WithJobToken("secret")) }, true},
+		{"oauth token", func() (*gitlab.Client, error) {
+			return gitlab.NewClient(srv.URL, "", gitlab.WithOAuthToken("secret", nil))
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := tt.client()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = c.GetProject(p.ID)
+			if tt.ok && err != nil {
+				t.Fatalf("GetProject: %v", err)
+			}
+			if !tt.ok && !gitlab.IsUnauthorized(err) {
+				t.Fatalf("error = %v, want 401", err)
+			}
+		})
+	}
+
+	// 触发管道使用trigger token, 不需要认证请求头
+	c, err := gitlab.NewClient(srv.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.TriggerPipeline(p.ID, trigger.Token, map[string]string{"ENV": "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	triggered := srv.TriggeredPipelines()
+	if len(triggered) != 1 || triggered[0].Variables["ENV"] != "prod" {
+		t.Errorf("triggered pipelines = %+v", triggered)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/260by/gitlab
 
-go 1.12
+go 1.21
@@ -1,6 +1,7 @@
 package gitlab
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -21,11 +22,39 @@ type Group struct {
 	ParentID              int    `json:"parent_id"`
 }
 
+// ListGroupsOptions 获取组列表的过滤条件
+type ListGroupsOptions struct {
+	ListOptions
+	Search         string `url:"search,omitempty"`
+	Owned          *bool  `url:"owned,omitempty"`
+	AllAvailable   *bool  `url:"all_available,omitempty"`
+	MinAccessLevel int    `url:"min_access_level,omitempty"`
+	SkipGroups     []int  `url:"skip_groups[],omitempty"`
+	OrderBy        string `url:"order_by,omitempty"` // name, path, id
+	Sort           string `url:"sort,omitempty"`     // asc, desc
+}
+
+// ListGroupProjectsOptions 获取组下仓库列表的过滤条件
+type ListGroupProjectsOptions struct {
+	ListProjectsOptions
+	IncludeSubGroups *bool `url:"include_subgroups,omitempty"`
+	WithShared       *bool `url:"with_shared,omitempty"`
+}
+
 // ListGroups 获取所有组
 func (c *Client) ListGroups() ([]Group, error) {
-	var groups []Group
+	return c.ListGroupsWithContext(context.Background(), nil)
+}
 
-	err := c.GetResourceList("/groups", &groups)
+// ListGroupsWithContext 获取组列表, opt可以为nil, 请求绑定ctx
+func (c *Client) ListGroupsWithContext(ctx context.Context, opt *ListGroupsOptions) ([]Group, error) {
+	api, err := addQuery("/groups", opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []Group
+	err = c.GetResourceListWithContext(ctx, api, &groups)
 	if err != nil {
 		return nil, err
 	}
@@ -34,10 +63,23 @@ func (c *Client) ListGroups() ([]Group, error) {
 }
 
 // ListSubGroups 获取指定组下的子组
-func (c *Client) ListSubGroups(groupID int) ([]Group, error) {
-	var subGroups []Group
+func (c *Client) ListSubGroups(groupID interface{}) ([]Group, error) {
+	return c.ListSubGroupsWithContext(context.Background(), groupID, nil)
+}
 
-	err := c.GetResourceList(fmt.Sprintf("/groups/%v/subgroups", groupID), &subGroups)
+// ListSubGroupsWithContext 获取指定组下的子组, opt可以为nil, 请求绑定ctx
+func (c *Client) ListSubGroupsWithContext(ctx context.Context, groupID interface{}, opt *ListGroupsOptions) ([]Group, error) {
+	gid, err := pathID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	api, err := addQuery(fmt.Sprintf("/groups/%s/subgroups", gid), opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var subGroups []Group
+	err = c.GetResourceListWithContext(ctx, api, &subGroups)
 	if err != nil {
 		return nil, err
 	}
@@ -46,10 +88,23 @@ func (c *Client) ListSubGroups(groupID int) ([]Group, error) {
 }
 
 // ListGroupsProjects 获取指定组下面的仓库
-func (c *Client) ListGroupsProjects(groupID int) ([]Project, error) {
-	var projects []Project
+func (c *Client) ListGroupsProjects(groupID interface{}) ([]Project, error) {
+	return c.ListGroupsProjectsWithContext(context.Background(), groupID, nil)
+}
+
+// ListGroupsProjectsWithContext 获取指定组下面的仓库, opt可以为nil, 请求绑定ctx
+func (c *Client) ListGroupsProjectsWithContext(ctx context.Context, groupID interface{}, opt *ListGroupProjectsOptions) ([]Project, error) {
+	gid, err := pathID(groupID)
+	if err != nil {
+		return nil, err
+	}
+	api, err := addQuery(fmt.Sprintf("/groups/%s/projects", gid), opt)
+	if err != nil {
+		return nil, err
+	}
 
-	err := c.GetResourceList(fmt.Sprintf("/groups/%v/projects", groupID), &projects)
+	var projects []Project
+	err = c.GetResourceListWithContext(ctx, api, &projects)
 	if err != nil {
 		return nil, err
 	}
@@ -57,22 +112,61 @@ func (c *Client) ListGroupsProjects(groupID int) ([]Project, error) {
 	return projects, nil
 }
 
-// CreateSubGroup 增加子组
-func (c *Client) CreateSubGroup(newGroupName string, parentID int) (Group, error) {
-	var subGroup Group
+// CreateGroupOptions 创建组参数
+type CreateGroupOptions struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	ParentID    int    `json:"parent_id,omitempty"` // 为0时创建顶级组
+	Description string `json:"description,omitempty"`
+	Visibility  string `json:"visibility,omitempty"` // private, internal, public
+}
+
+// CreateGroup 创建组
+func (c *Client) CreateGroup(opt *CreateGroupOptions) (Group, error) {
+	return c.CreateGroupWithContext(context.Background(), opt)
+}
 
-	err := c.CreateResource(fmt.Sprintf("/groups?name=%s&path=%s&parent_id=%v&visibility=private", newGroupName, newGroupName, parentID), &subGroup)
+// CreateGroupWithContext 创建组, 请求绑定ctx
+func (c *Client) CreateGroupWithContext(ctx context.Context, opt *CreateGroupOptions) (Group, error) {
+	var group Group
+
+	err := c.SendResourceWithContext(ctx, "POST", "/groups", opt, &group)
 	if err != nil {
-		return subGroup, err
+		return group, err
 	}
 
-	return subGroup, nil
+	return group, nil
+}
+
+// CreateSubGroup 增加子组
+func (c *Client) CreateSubGroup(newGroupName string, parentID int) (Group, error) {
+	return c.CreateSubGroupWithContext(context.Background(), newGroupName, parentID)
+}
+
+// CreateSubGroupWithContext 增加子组, 请求绑定ctx
+func (c *Client) CreateSubGroupWithContext(ctx context.Context, newGroupName string, parentID int) (Group, error) {
+	return c.CreateGroupWithContext(ctx, &CreateGroupOptions{
+		Name:       newGroupName,
+		Path:       newGroupName,
+		ParentID:   parentID,
+		Visibility: "private",
+	})
 }
 
 // GetGroup details of a group
-func (c *Client) GetGroup(groupID int) (Group, error) {
+func (c *Client) GetGroup(groupID interface{}) (Group, error) {
+	return c.GetGroupWithContext(context.Background(), groupID)
+}
+
+// GetGroupWithContext details of a group, the request is bound to ctx
+func (c *Client) GetGroupWithContext(ctx context.Context, groupID interface{}) (Group, error) {
 	var group Group
-	err := c.GetResource(fmt.Sprintf("/groups/%v", groupID), &group)
+	gid, err := pathID(groupID)
+	if err != nil {
+		return group, err
+	}
+
+	err = c.GetResourceWithContext(ctx, fmt.Sprintf("/groups/%s", gid), &group)
 	if err != nil {
 		return group, err
 	}
@@ -0,0 +1,78 @@
+package instrumentation
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/260by/gitlab"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics gitlab api请求的Prometheus指标
+type Metrics struct {
+	duration           *prometheus.HistogramVec
+	requests           *prometheus.CounterVec
+	retries            *prometheus.CounterVec
+	rateLimitRemaining prometheus.Gauge
+}
+
+// NewMetrics 创建并注册指标, reg为nil时不注册
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "gitlab_client",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of GitLab API requests by route template.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "gitlab_client",
+			Name:      "requests_total",
+			Help:      "GitLab API requests by route template and status code.",
+		}, []string{"method", "route", "code"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "gitlab_client",
+			Name:      "retries_total",
+			Help:      "GitLab API request retries by route template.",
+		}, []string{"method", "route"}),
+		rateLimitRemaining: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "gitlab_client",
+			Name:      "rate_limit_remaining",
+			Help:      "Value of the RateLimit-Remaining header from the last GitLab API response.",
+		}),
+	}
+
+	if reg != nil {
+		for _, c := range []prometheus.Collector{m.duration, m.requests, m.retries, m.rateLimitRemaining} {
+			if err := reg.Register(c); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// observe 记录一次请求, 连接错误的状态码记为error
+func (m *Metrics) observe(req *http.Request, res *http.Response, elapsed time.Duration) {
+	route := RoutePattern(req.URL.EscapedPath())
+
+	code := "error"
+	if res != nil {
+		code = strconv.Itoa(res.StatusCode)
+		if v, err := strconv.ParseFloat(res.Header.Get("RateLimit-Remaining"), 64); err == nil {
+			m.rateLimitRemaining.Set(v)
+		}
+	}
+
+	m.duration.WithLabelValues(req.Method, route).Observe(elapsed.Seconds())
+	m.requests.WithLabelValues(req.Method, route, code).Inc()
+}
+
+// RetryHook 返回统计重试次数的gitlab.RetryHook, 通过gitlab.WithRetryHook接入
+func (m *Metrics) RetryHook() gitlab.RetryHook {
+	return func(attempt int, req *http.Request, res *http.Response, err error, wait time.Duration) {
+		m.retries.WithLabelValues(req.Method, RoutePattern(req.URL.EscapedPath())).Inc()
+	}
+}
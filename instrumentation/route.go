@@ -0,0 +1,58 @@
+// Package instrumentation 为gitlab.Client提供Prometheus指标和OpenTelemetry链路追踪,
+// 通过包装http.RoundTripper接入. 该包是独立的module, 核心库不依赖Prometheus和OpenTelemetry:
+//
+//	metrics, err := instrumentation.NewMetrics(prometheus.DefaultRegisterer)
+//	...
+//	c, err := gitlab.NewClient(baseURL, token,
+//		gitlab.WithTransport(instrumentation.NewTransport(http.DefaultTransport, metrics, otel.Tracer("gitlab"))),
+//		gitlab.WithRetryHook(metrics.RetryHook()),
+//	)
+package instrumentation
+
+import (
+	"regexp"
+	"strings"
+)
+
+const apiVersionPath = "/api/v4"
+
+var numeric = regexp.MustCompile(`^[0-9]+$`)
+
+// idCollections 后面跟随资源ID(数字或编码后的完整路径)的路径段
+var idCollections = map[string]bool{
+	"projects": true,
+	"groups":   true,
+	"users":    true,
+}
+
+// RoutePattern 将请求路径转换为路由模板, 用作指标标签和span名称, 避免标签基数过高.
+// 如 /api/v4/projects/12/pipelines/34/jobs 转换为 /projects/:id/pipelines/:id/jobs,
+// path需要使用URL.EscapedPath(), 使group%2Fproject这类路径保持为一个路径段
+func RoutePattern(path string) string {
+	path = strings.TrimPrefix(path, apiVersionPath)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, seg := range segments {
+		if seg == "" {
+			continue
+		}
+
+		prev := ""
+		if i > 0 {
+			prev = segments[i-1]
+		}
+
+		switch {
+		case numeric.MatchString(seg) || idCollections[prev]:
+			segments[i] = ":id"
+		case prev == "files":
+			segments[i] = ":file_path"
+		case prev == "commits":
+			segments[i] = ":sha"
+		case prev == "branches" || prev == "tags":
+			segments[i] = ":name"
+		}
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
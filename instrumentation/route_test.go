@@ -0,0 +1,38 @@
+package instrumentation
+
+import "testing"
+
+func TestRoutePattern(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v4/projects", "/projects"},
+		{"/api/v4/projects/12", "/projects/:id"},
+		{"/api/v4/projects/team%2Fapp", "/projects/:id"},
+		{"/api/v4/projects/team%2Fsub%2Fapp/pipelines", "/projects/:id/pipelines"},
+		{"/api/v4/projects/12/pipelines/34/jobs", "/projects/:id/pipelines/:id/jobs"},
+		{"/api/v4/projects/12/pipelines/34/variables", "/projects/:id/pipelines/:id/variables"},
+		{"/api/v4/projects/12/repository/tree", "/projects/:id/repository/tree"},
+		{"/api/v4/projects/12/jobs/56/retry", "/projects/:id/jobs/:id/retry"},
+		{"/api/v4/projects/team%2Fapp/members/all", "/projects/:id/members/all"},
+		{"/api/v4/projects/12/members/7", "/projects/:id/members/:id"},
+		{"/api/v4/projects/12/share/3", "/projects/:id/share/:id"},
+		{"/api/v4/projects/12/invitations", "/projects/:id/invitations"},
+		{"/api/v4/projects/12/trigger/pipeline", "/projects/:id/trigger/pipeline"},
+		{"/api/v4/projects/12/repository/files/.gitlab-ci.yml", "/projects/:id/repository/files/:file_path"},
+		{"/api/v4/projects/12/repository/files/ci%2Fbuild.yml/raw", "/projects/:id/repository/files/:file_path/raw"},
+		{"/api/v4/projects/12/repository/commits/a1b2c3", "/projects/:id/repository/commits/:sha"},
+		{"/api/v4/projects/12/repository/branches/main", "/projects/:id/repository/branches/:name"},
+		{"/api/v4/groups/team%2Fsub/projects", "/groups/:id/projects"},
+		{"/api/v4/groups/5/subgroups", "/groups/:id/subgroups"},
+		{"/api/v4/users/root", "/users/:id"},
+		{"/projects/12/hooks/9", "/projects/:id/hooks/:id"},
+	}
+
+	for _, tt := range tests {
+		if got := RoutePattern(tt.path); got != tt.want {
+			t.Errorf("RoutePattern(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
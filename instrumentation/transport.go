@@ -0,0 +1,72 @@
+package instrumentation
+
+import (
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport 记录指标和span的http.RoundTripper
+type Transport struct {
+	base    http.RoundTripper
+	metrics *Metrics
+	tracer  trace.Tracer
+}
+
+// NewTransport 包装base, base为nil时使用http.DefaultTransport; metrics和tracer均可为nil
+func NewTransport(base http.RoundTripper, metrics *Metrics, tracer trace.Tracer) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{base: base, metrics: metrics, tracer: tracer}
+}
+
+// RoundTrip 发送请求, span名称为 "方法 路由模板", 如 GET /projects/:id/jobs/:id
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	route := RoutePattern(req.URL.EscapedPath())
+
+	if t.tracer != nil {
+		ctx, span := t.tracer.Start(req.Context(), req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("http.request.method", req.Method),
+				attribute.String("http.route", route),
+				attribute.String("server.address", req.URL.Host),
+			),
+		)
+		defer span.End()
+
+		// RoundTripper不能修改传入的请求
+		req = req.Clone(ctx)
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+		res, err := t.roundTrip(req)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return res, err
+		}
+
+		span.SetAttributes(attribute.Int("http.response.status_code", res.StatusCode))
+		if res.StatusCode >= 400 {
+			span.SetStatus(codes.Error, res.Status)
+		}
+		return res, nil
+	}
+
+	return t.roundTrip(req)
+}
+
+func (t *Transport) roundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.base.RoundTrip(req)
+	if t.metrics != nil {
+		t.metrics.observe(req, res, time.Since(start))
+	}
+	return res, err
+}
@@ -0,0 +1,124 @@
+package instrumentation
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// roundTripFunc 返回固定响应的base transport
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// recordingTracer 记录span名称
+type recordingTracer struct {
+	noop.Tracer
+	spans []string
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t.spans = append(t.spans, name)
+	return t.Tracer.Start(ctx, name, opts...)
+}
+
+func TestTransport(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics, err := NewMetrics(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer := &recordingTracer{}
+
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		status := http.StatusOK
+		if strings.HasSuffix(req.URL.Path, "/retry") {
+			status = http.StatusForbidden
+		}
+		return &http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Header:     http.Header{"Ratelimit-Remaining": {"42"}},
+			Body:       io.NopCloser(strings.NewReader("{}")),
+			Request:    req,
+		}, nil
+	})
+	tr := NewTransport(base, metrics, tracer)
+
+	for _, u := range []string{
+		"https://gitlab.example.com/api/v4/projects/team%2Fapp/pipelines/34/jobs",
+		"https://gitlab.example.com/api/v4/projects/12/pipelines/35/jobs",
+		"https://gitlab.example.com/api/v4/projects/12/jobs/56/retry",
+	} {
+		method := "GET"
+		if strings.HasSuffix(u, "/retry") {
+			method = "POST"
+		}
+		req, err := http.NewRequest(method, u, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := tr.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if len(req.Header) != 0 {
+			t.Errorf("RoundTrip modified the request headers: %v", req.Header)
+		}
+	}
+
+	wantSpans := []string{
+		"GET /projects/:id/pipelines/:id/jobs",
+		"GET /projects/:id/pipelines/:id/jobs",
+		"POST /projects/:id/jobs/:id/retry",
+	}
+	if strings.Join(tracer.spans, ",") != strings.Join(wantSpans, ",") {
+		t.Errorf("spans = %q, want %q", tracer.spans, wantSpans)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := make(map[string]float64)
+	var remaining float64
+	for _, f := range families {
+		switch f.GetName() {
+		case "gitlab_client_requests_total":
+			for _, m := range f.GetMetric() {
+				labels := make(map[string]string)
+				for _, l := range m.GetLabel() {
+					labels[l.GetName()] = l.GetValue()
+				}
+				requests[labels["method"]+" "+labels["route"]+" "+labels["code"]] = m.GetCounter().GetValue()
+			}
+		case "gitlab_client_rate_limit_remaining":
+			remaining = f.GetMetric()[0].GetGauge().GetValue()
+		}
+	}
+
+	wantRequests := map[string]float64{
+		"GET /projects/:id/pipelines/:id/jobs 200": 2,
+		"POST /projects/:id/jobs/:id/retry 403":    1,
+	}
+	if len(requests) != len(wantRequests) {
+		t.Errorf("requests_total = %v, want %v", requests, wantRequests)
+	}
+	for k, v := range wantRequests {
+		if requests[k] != v {
+			t.Errorf("requests_total{%s} = %v, want %v", k, requests[k], v)
+		}
+	}
+	if remaining != 42 {
+		t.Errorf("rate_limit_remaining = %v, want 42", remaining)
+	}
+}
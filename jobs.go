@@ -1,23 +1,27 @@
 package gitlab
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"time"
 )
 
 // Job gitlab job
 type Job struct {
-	ID           int     `json:"id"`
-	Status       string  `json:"status"`
-	Stage        string  `json:"stage"`
-	Name         string  `json:"name"`
-	Ref          string  `json:"ref"`
-	Tag          bool    `json:"tag"`
-	Coverage     string  `json:"coverage"`
-	AllowFailure bool    `json:"allow_failure"`
-	CreatedAt    string  `json:"created_at"`
-	StartedAt    string  `json:"started_at"`
-	FinishedAt   string  `json:"finished_at"`
-	Duration     float32 `json:"duration"`
+	ID           int        `json:"id"`
+	Status       string     `json:"status"`
+	Stage        string     `json:"stage"`
+	Name         string     `json:"name"`
+	Ref          string     `json:"ref"`
+	Tag          bool       `json:"tag"`
+	Coverage     string     `json:"coverage"`
+	AllowFailure bool       `json:"allow_failure"`
+	CreatedAt    *time.Time `json:"created_at"`
+	StartedAt    *time.Time `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+	Duration     float32    `json:"duration"`
 	User         struct {
 		ID           int    `json:"id"`
 		Name         string `json:"name"`
@@ -68,7 +72,53 @@ type Job struct {
 		Online      bool   `json:"online"`
 		Status      string `json:"status"`
 	} `json:"runner"`
-	ArtifactsExpireAt string `json:"artifacts_expire_at"`
+	ArtifactsExpireAt *time.Time `json:"artifacts_expire_at"`
+}
+
+// UnmarshalJSON parse gitlab timestamps, null or empty times are left nil
+func (j *Job) UnmarshalJSON(data []byte) error {
+	type alias Job
+	aux := struct {
+		*alias
+		CreatedAt         flexTime `json:"created_at"`
+		StartedAt         flexTime `json:"started_at"`
+		FinishedAt        flexTime `json:"finished_at"`
+		ArtifactsExpireAt flexTime `json:"artifacts_expire_at"`
+	}{alias: (*alias)(j)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	j.CreatedAt = aux.CreatedAt.t
+	j.StartedAt = aux.StartedAt.t
+	j.FinishedAt = aux.FinishedAt.t
+	j.ArtifactsExpireAt = aux.ArtifactsExpireAt.t
+
+	return nil
+}
+
+// QueuedDuration time the job waited for a runner, up to now if it has not started yet,
+// 0 if the creation time is unknown
+func (j *Job) QueuedDuration() time.Duration {
+	if j.CreatedAt == nil {
+		return 0
+	}
+	if j.StartedAt == nil {
+		return time.Since(*j.CreatedAt)
+	}
+	return j.StartedAt.Sub(*j.CreatedAt)
+}
+
+// WallTime time the job has been running, up to now if it has not finished yet,
+// 0 if it has not started
+func (j *Job) WallTime() time.Duration {
+	if j.StartedAt == nil {
+		return 0
+	}
+	if j.FinishedAt == nil {
+		return time.Since(*j.StartedAt)
+	}
+	return j.FinishedAt.Sub(*j.StartedAt)
 }
 
 // Artifact job artifact
@@ -79,10 +129,31 @@ type Artifact struct {
 	FileFormat string `json:"file_format"`
 }
 
+// ListJobsOptions list jobs options
+type ListJobsOptions struct {
+	ListOptions
+	Scope          []string `url:"scope[],omitempty"`         // created, pending, running, failed, success, canceled, skipped, manual
+	IncludeRetried *bool    `url:"include_retried,omitempty"` // only for pipeline jobs
+}
+
 // ListPipelineJobs get a list of jobs for a pipeline
-func (c *Client) ListPipelineJobs(projectID, pipelineID int) ([]Job, error) {
+func (c *Client) ListPipelineJobs(projectID interface{}, pipelineID int) ([]Job, error) {
+	return c.ListPipelineJobsWithContext(context.Background(), projectID, pipelineID, nil)
+}
+
+// ListPipelineJobsWithContext get a list of jobs for a pipeline filtered by opt (may be nil), the requests are bound to ctx
+func (c *Client) ListPipelineJobsWithContext(ctx context.Context, projectID interface{}, pipelineID int, opt *ListJobsOptions) ([]Job, error) {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	api, err := addQuery(fmt.Sprintf("/projects/%s/pipelines/%v/jobs", pid, pipelineID), opt)
+	if err != nil {
+		return nil, err
+	}
+
 	var jobs []Job
-	err := c.GetResourceList(fmt.Sprintf("/projects/%v/pipelines/%v/jobs", projectID, pipelineID), &jobs)
+	err = c.GetResourceListWithContext(ctx, api, &jobs)
 	if err != nil {
 		return nil, err
 	}
@@ -91,9 +162,23 @@ func (c *Client) ListPipelineJobs(projectID, pipelineID int) ([]Job, error) {
 }
 
 // ListProjectJobs get a list of jobs in a project
-func (c *Client) ListProjectJobs(projectID int) ([]Job, error) {
+func (c *Client) ListProjectJobs(projectID interface{}) ([]Job, error) {
+	return c.ListProjectJobsWithContext(context.Background(), projectID, nil)
+}
+
+// ListProjectJobsWithContext get a list of jobs in a project filtered by opt (may be nil), the requests are bound to ctx
+func (c *Client) ListProjectJobsWithContext(ctx context.Context, projectID interface{}, opt *ListJobsOptions) ([]Job, error) {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	api, err := addQuery(fmt.Sprintf("/projects/%s/jobs", pid), opt)
+	if err != nil {
+		return nil, err
+	}
+
 	var jobs []Job
-	err := c.GetResourceList(fmt.Sprintf("/projects/%v/jobs", projectID), &jobs)
+	err = c.GetResourceListWithContext(ctx, api, &jobs)
 	if err != nil {
 		return jobs, err
 	}
@@ -101,10 +186,43 @@ func (c *Client) ListProjectJobs(projectID int) ([]Job, error) {
 	return jobs, nil
 }
 
+// ListProjectJobsKeyset get a list of jobs in a project using keyset pagination,
+// gitlab only supports ordering jobs by id desc
+func (c *Client) ListProjectJobsKeyset(projectID interface{}) ([]Job, error) {
+	return c.ListProjectJobsKeysetWithContext(context.Background(), projectID)
+}
+
+// ListProjectJobsKeysetWithContext get a list of jobs in a project using keyset pagination, the requests are bound to ctx
+func (c *Client) ListProjectJobsKeysetWithContext(ctx context.Context, projectID interface{}) ([]Job, error) {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []Job
+	opt := KeysetOptions{OrderBy: "id", Sort: "desc"}
+	err = c.GetResourceListKeysetWithContext(ctx, fmt.Sprintf("/projects/%s/jobs", pid), opt, &jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
 // ActionJob play or retry a job
-func (c *Client) ActionJob(projectID, jobID int, action string) (Job, error) {
+func (c *Client) ActionJob(projectID interface{}, jobID int, action string) (Job, error) {
+	return c.ActionJobWithContext(context.Background(), projectID, jobID, action)
+}
+
+// ActionJobWithContext play or retry a job, the request is bound to ctx
+func (c *Client) ActionJobWithContext(ctx context.Context, projectID interface{}, jobID int, action string) (Job, error) {
 	var job Job
-	err := c.CreateResource(fmt.Sprintf("/projects/%v/jobs/%v/%s", projectID, jobID, action), &job)
+	pid, err := pathID(projectID)
+	if err != nil {
+		return job, err
+	}
+
+	err = c.CreateResourceWithContext(ctx, fmt.Sprintf("/projects/%s/jobs/%v/%s", pid, jobID, url.PathEscape(action)), &job)
 	if err != nil {
 		return job, err
 	}
@@ -113,12 +231,22 @@ func (c *Client) ActionJob(projectID, jobID int, action string) (Job, error) {
 }
 
 // GetJob get a single job
-func (c *Client) GetJob(projectID, jobID int) (Job, error) {
+func (c *Client) GetJob(projectID interface{}, jobID int) (Job, error) {
+	return c.GetJobWithContext(context.Background(), projectID, jobID)
+}
+
+// GetJobWithContext get a single job, the request is bound to ctx
+func (c *Client) GetJobWithContext(ctx context.Context, projectID interface{}, jobID int) (Job, error) {
 	var job Job
-	err := c.GetResource(fmt.Sprintf("/projects/%v/jobs/%v", projectID, jobID), &job)
+	pid, err := pathID(projectID)
+	if err != nil {
+		return job, err
+	}
+
+	err = c.GetResourceWithContext(ctx, fmt.Sprintf("/projects/%s/jobs/%v", pid, jobID), &job)
 	if err != nil {
 		return job, err
 	}
 
 	return job, nil
-}
\ No newline at end of file
+}
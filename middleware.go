@@ -0,0 +1,86 @@
+package gitlab
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// RequestHandler 发送单次http请求并返回响应
+type RequestHandler func(req *http.Request) (*http.Response, error)
+
+// Middleware 包装RequestHandler, 可在请求前后修改请求、记录日志等. 每次重试都会经过middleware
+type Middleware func(next RequestHandler) RequestHandler
+
+// WithMiddleware 增加middleware, 先添加的在外层
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) error {
+		c.middlewares = append(c.middlewares, mw...)
+		return nil
+	}
+}
+
+// handler 组合middleware, 最内层使用http.Client发送请求
+func (c *Client) handler() RequestHandler {
+	h := RequestHandler(c.client().Do)
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		h = c.middlewares[i](h)
+	}
+	return h
+}
+
+// RequestMutator 返回在发送前修改请求的middleware, 如增加自定义请求头
+func RequestMutator(mutate func(req *http.Request)) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(req *http.Request) (*http.Response, error) {
+			mutate(req)
+			return next(req)
+		}
+	}
+}
+
+// LoggingMiddleware 使用slog记录每次请求的方法、路径、状态码、耗时和request id,
+// Debug级别时额外记录隐藏了敏感信息的请求头
+func LoggingMiddleware(logger *slog.Logger) Middleware {
+	return func(next RequestHandler) RequestHandler {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			start := time.Now()
+			res, err := next(req)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", req.URL.Path),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if logger.Enabled(ctx, slog.LevelDebug) {
+				attrs = append(attrs, slog.Any("headers", RedactHeaders(req.Header)))
+			}
+
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+				logger.LogAttrs(ctx, slog.LevelError, "gitlab request failed", attrs...)
+				return res, err
+			}
+
+			attrs = append(attrs,
+				slog.Int("status", res.StatusCode),
+				slog.String("request_id", res.Header.Get("X-Request-Id")),
+			)
+			logger.LogAttrs(ctx, levelForStatus(res.StatusCode), "gitlab request", attrs...)
+
+			return res, nil
+		}
+	}
+}
+
+// levelForStatus 5xx记录为Error, 4xx为Warn, 其余为Info
+func levelForStatus(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	}
+	return slog.LevelInfo
+}
@@ -0,0 +1,104 @@
+package gitlab_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/260by/gitlab"
+)
+
+func TestLoggingMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-123")
+		if r.URL.Path == "/api/v4/projects/2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name  string
+		level slog.Level
+		auth  gitlab.Option
+	}{
+		{"private token debug", slog.LevelDebug, nil},
+		{"oauth debug", slog.LevelDebug, gitlab.WithOAuthToken("oauth-secret", nil)},
+		{"info", slog.LevelInfo, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: tt.level}))
+			opts := []gitlab.Option{gitlab.WithMiddleware(gitlab.LoggingMiddleware(logger))}
+			if tt.auth != nil {
+				opts = append(opts, tt.auth)
+			}
+			c, err := gitlab.NewClient(srv.URL, "private-secret", opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := c.GetProject(1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.GetProject(2); !gitlab.IsNotFound(err) {
+				t.Fatalf("error = %v, want 404", err)
+			}
+
+			if strings.Contains(buf.String(), "secret") {
+				t.Errorf("log contains a token:\n%s", buf.String())
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 2 {
+				t.Fatalf("got %d log lines, want 2:\n%s", len(lines), buf.String())
+			}
+
+			var records [2]struct {
+				Level     string              `json:"level"`
+				Msg       string              `json:"msg"`
+				Method    string              `json:"method"`
+				Path      string              `json:"path"`
+				Status    int                 `json:"status"`
+				RequestID string              `json:"request_id"`
+				Headers   map[string][]string `json:"headers"`
+			}
+			for i, line := range lines {
+				if err := json.Unmarshal([]byte(line), &records[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ok, notFound := records[0], records[1]
+			if ok.Level != "INFO" || ok.Msg != "gitlab request" || ok.Method != "GET" ||
+				ok.Path != "/api/v4/projects/1" || ok.Status != 200 || ok.RequestID != "req-123" {
+				t.Errorf("log record = %+v", ok)
+			}
+			if notFound.Level != "WARN" || notFound.Status != 404 {
+				t.Errorf("404 log record = %+v", notFound)
+			}
+
+			if tt.level != slog.LevelDebug {
+				if ok.Headers != nil {
+					t.Errorf("headers logged at info level: %v", ok.Headers)
+				}
+				return
+			}
+			header := "Private-Token"
+			if tt.auth != nil {
+				header = "Authorization"
+			}
+			if v := ok.Headers[header]; len(v) != 1 || v[0] != gitlab.Redacted {
+				t.Errorf("%s = %q, want %s", header, v, gitlab.Redacted)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PageInfo 列表接口响应头中的分页信息
+type PageInfo struct {
+	Page       int               // X-Page
+	PerPage    int               // X-Per-Page
+	NextPage   int               // X-Next-Page, 最后一页为0
+	PrevPage   int               // X-Prev-Page
+	Total      int               // X-Total, 超过10000条时gitlab不返回, 为0
+	TotalPages int               // X-Total-Pages, 同上
+	Links      map[string]string // Link响应头, key为rel, 如next, prev, first, last
+}
+
+// parsePageInfo 解析分页响应头
+func parsePageInfo(h http.Header) PageInfo {
+	atoi := func(key string) int {
+		n, _ := strconv.Atoi(h.Get(key))
+		return n
+	}
+
+	return PageInfo{
+		Page:       atoi("X-Page"),
+		PerPage:    atoi("X-Per-Page"),
+		NextPage:   atoi("X-Next-Page"),
+		PrevPage:   atoi("X-Prev-Page"),
+		Total:      atoi("X-Total"),
+		TotalPages: atoi("X-Total-Pages"),
+		Links:      parseLinkHeader(h.Get("Link")),
+	}
+}
+
+// parseLinkHeader 解析 <url>; rel="next", <url>; rel="first" 格式的Link响应头
+func parseLinkHeader(link string) map[string]string {
+	links := make(map[string]string)
+	for _, part := range strings.Split(link, ",") {
+		segments := strings.Split(strings.TrimSpace(part), ";")
+		if len(segments) < 2 {
+			continue
+		}
+
+		target := strings.TrimSpace(segments[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		target = target[1 : len(target)-1]
+
+		for _, param := range segments[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && strings.TrimSpace(kv[0]) == "rel" {
+				for _, rel := range strings.Fields(strings.Trim(kv[1], `"`)) {
+					links[rel] = target
+				}
+			}
+		}
+	}
+
+	return links
+}
+
+// ListIterator 逐页获取列表资源, 每页单独解析, 可随时停止
+//
+//	it := c.NewListIterator("/projects")
+//	var page []Project
+//	for it.Next(ctx, &page) {
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ListIterator struct {
+	c      *Client
+	next   string
+	cur    *url.URL
+	page   PageInfo
+	err    error
+	keyset bool
+}
+
+// NewListIterator 创建api的分页迭代器, api可以带查询参数
+func (c *Client) NewListIterator(api string) *ListIterator {
+	it := &ListIterator{c: c}
+
+	u, err := url.Parse(c.endpoint(api))
+	if err != nil {
+		it.err = err
+		return it
+	}
+	q := u.Query()
+	if q.Get("page") == "" {
+		q.Set("page", "1")
+	}
+	u.RawQuery = q.Encode()
+	it.next = u.String()
+
+	return it
+}
+
+// KeysetOptions keyset分页参数, 大量数据时比page分页更快, 且不受页数上限限制
+type KeysetOptions struct {
+	OrderBy string // 排序字段, 默认id
+	Sort    string // asc或desc, 默认asc
+	PerPage int    // 每页数量, 默认100
+}
+
+// NewKeysetIterator 创建keyset分页迭代器, 通过Link响应头中的rel="next"获取下一页.
+// 只有部分接口支持keyset分页, 如/projects, /projects/:id/jobs
+func (c *Client) NewKeysetIterator(api string, opt KeysetOptions) *ListIterator {
+	it := &ListIterator{c: c, keyset: true}
+
+	u, err := url.Parse(c.endpoint(api))
+	if err != nil {
+		it.err = err
+		return it
+	}
+
+	if opt.OrderBy == "" {
+		opt.OrderBy = "id"
+	}
+	if opt.Sort == "" {
+		opt.Sort = "asc"
+	}
+	if opt.PerPage <= 0 {
+		opt.PerPage = 100
+	}
+
+	q := u.Query()
+	q.Del("page")
+	q.Set("pagination", "keyset")
+	q.Set("order_by", opt.OrderBy)
+	q.Set("sort", opt.Sort)
+	q.Set("per_page", strconv.Itoa(opt.PerPage))
+	u.RawQuery = q.Encode()
+	it.next = u.String()
+
+	return it
+}
+
+// Next 获取下一页并解析到v(指向切片的指针), 没有更多数据或出错时返回false
+func (it *ListIterator) Next(ctx context.Context, v interface{}) bool {
+	if it.err != nil || it.next == "" {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		it.err = errors.New("gitlab: ListIterator.Next requires a non-nil pointer")
+		return false
+	}
+
+	req, err := it.c.newRequest(ctx, "GET", it.next, nil)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	// 清空上一页的数据, 避免json复用切片元素时残留字段
+	rv.Elem().Set(reflect.Zero(rv.Elem().Type()))
+	res, err := it.c.Do(req, v)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.cur = req.URL
+	it.page = parsePageInfo(res.Header)
+	it.next = it.nextURL(req.URL, res.Header)
+
+	return true
+}
+
+// Err 返回迭代过程中的错误
+func (it *ListIterator) Err() error {
+	return it.err
+}
+
+// PageInfo 返回最近一次获取的分页信息
+func (it *ListIterator) PageInfo() PageInfo {
+	return it.page
+}
+
+// nextURL 优先使用Link响应头中的rel="next", 其次使用X-Next-Page, 最后根据X-Total-Pages判断.
+// keyset分页只使用Link响应头
+func (it *ListIterator) nextURL(current *url.URL, h http.Header) string {
+	if next, ok := it.page.Links["next"]; ok {
+		if u, err := it.sameOrigin(next); err == nil {
+			return u
+		}
+	}
+	if it.keyset {
+		return ""
+	}
+
+	u := *current
+	q := u.Query()
+	page, _ := strconv.Atoi(q.Get("page"))
+
+	if _, ok := h["X-Next-Page"]; ok {
+		if it.page.NextPage == 0 {
+			return ""
+		}
+		page = it.page.NextPage
+	} else if it.page.TotalPages > 0 && page < it.page.TotalPages {
+		page++
+	} else {
+		return ""
+	}
+
+	q.Set("page", strconv.Itoa(page))
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// sameOrigin Link中的地址使用BaseURL的scheme和host, 避免token被发送到其他地址
+func (it *ListIterator) sameOrigin(link string) (string, error) {
+	l, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(it.c.BaseURL)
+	if err != nil {
+		return "", err
+	}
+
+	u.Path = l.Path
+	u.RawPath = l.RawPath
+	u.RawQuery = l.RawQuery
+
+	return u.String(), nil
+}
+
+// WithParallelPages 开启并发获取分页, 获取第一页后根据X-Total-Pages用workers个goroutine获取剩余页面,
+// 结果按页码顺序合并. 只对GetResourceList等一次性获取全部数据的方法生效, keyset分页不支持
+func WithParallelPages(workers int) Option {
+	return func(c *Client) error {
+		c.pageWorkers = workers
+		return nil
+	}
+}
+
+// canFetchParallel 是否可以并发获取剩余页面
+func (it *ListIterator) canFetchParallel() bool {
+	return it.c.pageWorkers > 1 && !it.keyset && it.err == nil && it.next != "" &&
+		it.cur != nil && it.page.Page > 0 && it.page.TotalPages > it.page.Page
+}
+
+// fetchRemaining 并发获取剩余的所有页面, 按页码顺序返回, 每个元素为sliceType类型的切片
+func (it *ListIterator) fetchRemaining(ctx context.Context, sliceType reflect.Type) ([]reflect.Value, error) {
+	first, last := it.page.Page+1, it.page.TotalPages
+	pages := make([]reflect.Value, last-first+1)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	workers := it.c.pageWorkers
+	if workers > len(pages) {
+		workers = len(pages)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	jobs := make(chan int)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for page := range jobs {
+				v, err := it.fetchPage(ctx, page, sliceType)
+				if err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				pages[page-first] = v
+			}
+		}()
+	}
+
+send:
+	for page := first; page <= last; page++ {
+		select {
+		case jobs <- page:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	it.next = ""
+	return pages, nil
+}
+
+// fetchPage 获取指定页码
+func (it *ListIterator) fetchPage(ctx context.Context, page int, sliceType reflect.Type) (reflect.Value, error) {
+	u := *it.cur
+	q := u.Query()
+	q.Set("page", strconv.Itoa(page))
+	u.RawQuery = q.Encode()
+
+	req, err := it.c.newRequest(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	v := reflect.New(sliceType)
+	if _, err := it.c.Do(req, v.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return v.Elem(), nil
+}
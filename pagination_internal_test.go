@@ -0,0 +1,69 @@
+package gitlab
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestNextURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		keyset bool
+		header http.Header
+		want   string
+	}{
+		{
+			name: "Link takes precedence over X-Next-Page",
+			header: http.Header{
+				"Link":        {`<https://other.example.com/api/v4/projects?page=5>; rel="next"`},
+				"X-Next-Page": {"3"},
+			},
+			want: "https://gitlab.example.com/api/v4/projects?page=5",
+		},
+		{
+			name:   "X-Next-Page",
+			header: http.Header{"X-Next-Page": {"3"}, "X-Total-Pages": {"9"}},
+			want:   "https://gitlab.example.com/api/v4/projects?page=3&per_page=20",
+		},
+		{
+			name:   "empty X-Next-Page ends the list",
+			header: http.Header{"X-Next-Page": {""}, "X-Total-Pages": {"9"}},
+			want:   "",
+		},
+		{
+			name:   "X-Total-Pages without X-Next-Page",
+			header: http.Header{"X-Total-Pages": {"4"}},
+			want:   "https://gitlab.example.com/api/v4/projects?page=3&per_page=20",
+		},
+		{
+			name:   "last page by X-Total-Pages",
+			header: http.Header{"X-Total-Pages": {"2"}},
+			want:   "",
+		},
+		{
+			name:   "keyset ignores X-Next-Page",
+			keyset: true,
+			header: http.Header{"X-Next-Page": {"3"}},
+			want:   "",
+		},
+		{
+			name:   "keyset follows Link",
+			keyset: true,
+			header: http.Header{"Link": {`<https://gitlab.example.com/api/v4/projects?id_after=40&pagination=keyset>; rel="next"`}},
+			want:   "https://gitlab.example.com/api/v4/projects?id_after=40&pagination=keyset",
+		},
+	}
+
+	current, _ := url.Parse("https://gitlab.example.com/api/v4/projects?page=2&per_page=20")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := &ListIterator{c: &Client{BaseURL: "https://gitlab.example.com"}, keyset: tt.keyset}
+			it.page = parsePageInfo(tt.header)
+
+			if got := it.nextURL(current, tt.header); got != tt.want {
+				t.Errorf("nextURL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
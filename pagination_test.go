@@ -0,0 +1,117 @@
+package gitlab_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/260by/gitlab"
+	"github.com/260by/gitlab/gitlabtest"
+)
+
+func TestGetResourceList(t *testing.T) {
+	tests := []struct {
+		name     string
+		workers  int
+		failPage int
+	}{
+		{"sequential", 0, 0},
+		{"parallel", 4, 0},
+		{"parallel more workers than pages", 16, 0},
+		{"sequential failure on page 3", 0, 3},
+		{"parallel failure on page 3", 4, 3},
+		{"parallel failure on last page", 4, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := gitlabtest.NewServer()
+			defer srv.Close()
+			for i := 0; i < 95; i++ {
+				srv.AddProject(gitlab.Project{Name: fmt.Sprintf("p%02d", i)})
+			}
+			if tt.failPage > 0 {
+				srv.FailNext("GET", fmt.Sprintf("/projects?page=%d", tt.failPage), http.StatusBadGateway, 1)
+			}
+
+			c, err := srv.Client(gitlab.WithParallelPages(tt.workers))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var projects []gitlab.Project
+			err = c.GetResourceListWithContext(context.Background(), "/projects?per_page=20", &projects)
+			if tt.failPage > 0 {
+				var e *gitlab.ErrorResponse
+				if !errors.As(err, &e) || e.StatusCode != http.StatusBadGateway {
+					t.Fatalf("error = %v, want 502", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(projects) != 95 {
+				t.Fatalf("got %d projects, want 95", len(projects))
+			}
+			for i, p := range projects {
+				if want := fmt.Sprintf("p%02d", i); p.Name != want {
+					t.Fatalf("project %d = %s, want %s", i, p.Name, want)
+				}
+			}
+		})
+	}
+}
+
+func TestKeysetIterator(t *testing.T) {
+	srv := gitlabtest.NewServer()
+	defer srv.Close()
+	for i := 0; i < 7; i++ {
+		srv.AddProject(gitlab.Project{Name: fmt.Sprintf("p%d", i)})
+	}
+
+	// keyset分页不支持并发, 开启后也应该逐页获取
+	c, err := srv.Client(gitlab.WithParallelPages(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it := c.NewKeysetIterator("/projects", gitlab.KeysetOptions{PerPage: 3})
+	var sizes []int
+	var page []gitlab.Project
+	for it.Next(context.Background(), &page) {
+		sizes = append(sizes, len(page))
+		if _, ok := it.PageInfo().Links["next"]; !ok && len(sizes) < 3 {
+			t.Errorf("page %d has no rel=next link", len(sizes))
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(sizes) != "[3 3 1]" {
+		t.Errorf("page sizes = %v, want [3 3 1]", sizes)
+	}
+}
+
+func TestListIteratorContextCanceled(t *testing.T) {
+	srv := gitlabtest.NewServer()
+	defer srv.Close()
+	srv.AddProject(gitlab.Project{Name: "demo"})
+
+	c, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var projects []gitlab.Project
+	if err := c.GetResourceListWithContext(ctx, "/projects", &projects); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
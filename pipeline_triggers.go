@@ -1,17 +1,20 @@
 package gitlab
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 )
 
 // Trigger 触发器信息
 type Trigger struct {
-	ID          int    `json:"id"`
-	Description string `json:"description"`
-	CreatedAt   string `json:"created_at"`
-	LastUsed    string `json:"last_used"`
-	Token       string `json:"token"`
-	UpdatedAt   string `json:"updated_at"`
+	ID          int        `json:"id"`
+	Description string     `json:"description"`
+	CreatedAt   *time.Time `json:"created_at"`
+	LastUsed    *time.Time `json:"last_used"`
+	Token       string     `json:"token"`
+	UpdatedAt   *time.Time `json:"updated_at"`
 	Owner       struct {
 		ID        int    `json:"id"`
 		Name      string `json:"name"`
@@ -22,10 +25,46 @@ type Trigger struct {
 	}
 }
 
+// UnmarshalJSON 解析gitlab时间, null或空字符串为nil
+func (t *Trigger) UnmarshalJSON(data []byte) error {
+	type alias Trigger
+	aux := struct {
+		*alias
+		CreatedAt flexTime `json:"created_at"`
+		LastUsed  flexTime `json:"last_used"`
+		UpdatedAt flexTime `json:"updated_at"`
+	}{alias: (*alias)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	t.CreatedAt = aux.CreatedAt.t
+	t.LastUsed = aux.LastUsed.t
+	t.UpdatedAt = aux.UpdatedAt.t
+
+	return nil
+}
+
+// CreateTriggerOptions 创建触发器参数
+type CreateTriggerOptions struct {
+	Description string `json:"description"`
+}
+
 // CreateTrigger 创建触发器
-func (c *Client) CreateTrigger(projectID int, description string) (Trigger, error) {
+func (c *Client) CreateTrigger(projectID interface{}, description string) (Trigger, error) {
+	return c.CreateTriggerWithContext(context.Background(), projectID, description)
+}
+
+// CreateTriggerWithContext 创建触发器, 请求绑定ctx
+func (c *Client) CreateTriggerWithContext(ctx context.Context, projectID interface{}, description string) (Trigger, error) {
 	var trigger Trigger
-	err := c.CreateResource(fmt.Sprintf("/projects/%v/triggers?description=%s", projectID, description), &trigger)
+	pid, err := pathID(projectID)
+	if err != nil {
+		return trigger, err
+	}
+
+	opt := &CreateTriggerOptions{Description: description}
+	err = c.SendResourceWithContext(ctx, "POST", fmt.Sprintf("/projects/%s/triggers", pid), opt, &trigger)
 	if err != nil {
 		return trigger, err
 	}
@@ -34,9 +73,19 @@ func (c *Client) CreateTrigger(projectID int, description string) (Trigger, erro
 }
 
 // GetTrigger 获取仓库触发器
-func (c *Client) GetTrigger(projectID int) (triggerToken string, err error) {
+func (c *Client) GetTrigger(projectID interface{}) (triggerToken string, err error) {
+	return c.GetTriggerWithContext(context.Background(), projectID)
+}
+
+// GetTriggerWithContext 获取仓库触发器, 请求绑定ctx
+func (c *Client) GetTriggerWithContext(ctx context.Context, projectID interface{}) (triggerToken string, err error) {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return triggerToken, err
+	}
+
 	var triggers []Trigger
-	err = c.GetResourceList(fmt.Sprintf("/projects/%v/triggers", projectID), &triggers)
+	err = c.GetResourceListWithContext(ctx, fmt.Sprintf("/projects/%s/triggers", pid), &triggers)
 	if err != nil {
 		return triggerToken, err
 	}
@@ -1,13 +1,9 @@
 package gitlab
 
 import (
-	"encoding/json"
-	"strconv"
-	"strings"
-	"io/ioutil"
-	"net/http"
-	"net/url"
+	"context"
 	"fmt"
+	"time"
 )
 
 // Pipeline gitlab pipeline struct
@@ -26,10 +22,40 @@ type Variable struct {
 	VariableType string `json:"variable_type"`
 }
 
+// ListPipelinesOptions list project pipelines options
+type ListPipelinesOptions struct {
+	ListOptions
+	Scope         string     `url:"scope,omitempty"`  // running, pending, finished, branches, tags
+	Status        string     `url:"status,omitempty"` // created, pending, running, success, failed, canceled, skipped, manual ...
+	Ref           string     `url:"ref,omitempty"`
+	SHA           string     `url:"sha,omitempty"`
+	Source        string     `url:"source,omitempty"` // push, web, trigger, schedule, api, pipeline ...
+	YamlErrors    *bool      `url:"yaml_errors,omitempty"`
+	Username      string     `url:"username,omitempty"`
+	UpdatedAfter  *time.Time `url:"updated_after,omitempty"`
+	UpdatedBefore *time.Time `url:"updated_before,omitempty"`
+	OrderBy       string     `url:"order_by,omitempty"` // id, status, ref, updated_at, user_id
+	Sort          string     `url:"sort,omitempty"`     // asc, desc
+}
+
 // ListPipelines list project pipelines
-func (c *Client) ListPipelines(projectID int) ([]Pipeline, error) {
+func (c *Client) ListPipelines(projectID interface{}) ([]Pipeline, error) {
+	return c.ListPipelinesWithContext(context.Background(), projectID, nil)
+}
+
+// ListPipelinesWithContext list project pipelines filtered by opt (may be nil), the requests are bound to ctx
+func (c *Client) ListPipelinesWithContext(ctx context.Context, projectID interface{}, opt *ListPipelinesOptions) ([]Pipeline, error) {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	api, err := addQuery(fmt.Sprintf("/projects/%s/pipelines", pid), opt)
+	if err != nil {
+		return nil, err
+	}
+
 	var pipelines []Pipeline
-	err := c.GetResourceList(fmt.Sprintf("/projects/%v/pipelines", projectID), &pipelines)
+	err = c.GetResourceListWithContext(ctx, api, &pipelines)
 	if err != nil {
 		return nil, err
 	}
@@ -38,9 +64,19 @@ func (c *Client) ListPipelines(projectID int) ([]Pipeline, error) {
 }
 
 // ListPipelineVar get variables of a pipeline
-func (c *Client) ListPipelineVar(projectID, pipelineID int) ([]Variable, error) {
+func (c *Client) ListPipelineVar(projectID interface{}, pipelineID int) ([]Variable, error) {
+	return c.ListPipelineVarWithContext(context.Background(), projectID, pipelineID)
+}
+
+// ListPipelineVarWithContext get variables of a pipeline, the requests are bound to ctx
+func (c *Client) ListPipelineVarWithContext(ctx context.Context, projectID interface{}, pipelineID int) ([]Variable, error) {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return nil, err
+	}
+
 	var variables []Variable
-	err := c.GetResourceList(fmt.Sprintf("/projects/%v/pipelines/%v/variables", projectID, pipelineID), &variables)
+	err = c.GetResourceListWithContext(ctx, fmt.Sprintf("/projects/%s/pipelines/%v/variables", pid, pipelineID), &variables)
 	if err != nil {
 		return nil, err
 	}
@@ -49,77 +85,22 @@ func (c *Client) ListPipelineVar(projectID, pipelineID int) ([]Variable, error)
 }
 
 // GetPipeline get a single pipeline
-func (c *Client) GetPipeline(projectID, pipelineID int) (Pipeline, error) {
+func (c *Client) GetPipeline(projectID interface{}, pipelineID int) (Pipeline, error) {
+	return c.GetPipelineWithContext(context.Background(), projectID, pipelineID)
+}
+
+// GetPipelineWithContext get a single pipeline, the request is bound to ctx
+func (c *Client) GetPipelineWithContext(ctx context.Context, projectID interface{}, pipelineID int) (Pipeline, error) {
 	var pipeline Pipeline
-	err := c.GetResource(fmt.Sprintf("/projects/%v/pipelines/%v", projectID, pipelineID), &pipeline)
+	pid, err := pathID(projectID)
 	if err != nil {
 		return pipeline, err
 	}
 
-	return pipeline, nil
-}
-
-// GetResourceList get gitlab resource list
-func (c *Client) requestPiplines(api string, v interface{}) error {
-	var response string
-	page := 1
-	for {
-		u, err := url.Parse(fmt.Sprintf("%s%s%s", c.BaseURL, apiVersionPath, api))
-		if err != nil {
-			return err
-		}
-		q := u.Query()
-		q.Set("page", fmt.Sprint(page))
-		u.RawQuery = q.Encode()
-
-		httpClient := &http.Client{}
-
-		req, err := http.NewRequest("GET", u.String(), nil)
-		if err != nil {
-			return err
-		}
-
-		req.Header.Set("Private-Token", c.AccessToken)
-
-		res, err := httpClient.Do(req)
-		if err != nil {
-			return err
-		}
-		defer res.Body.Close()
-
-		body, err := ioutil.ReadAll(res.Body)
-		if err != nil {
-			return err
-		}
-
-		if res.StatusCode < 200 || res.StatusCode > 299 {
-			return fmt.Errorf("request error response status code %v, response:%s", res.StatusCode, string(body))
-		}
-
-		response += string(body)
-
-		s := res.Header.Get("X-Total-Pages")
-		if s == "" {
-			break
-		}
-		totalPages, err := strconv.Atoi(s)
-		if err != nil {
-			return err
-		}
-
-		if page == totalPages {
-			break
-		}
-
-		page++
-	}
-
-	response = strings.ReplaceAll(response, "][", ",")
-
-	err := json.Unmarshal([]byte(response), &v)
+	err = c.GetResourceWithContext(ctx, fmt.Sprintf("/projects/%s/pipelines/%v", pid, pipelineID), &pipeline)
 	if err != nil {
-		return err
+		return pipeline, err
 	}
 
-	return nil
-}
\ No newline at end of file
+	return pipeline, nil
+}
@@ -0,0 +1,315 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AccessLevel 成员权限级别
+type AccessLevel int
+
+// gitlab定义的权限级别
+const (
+	NoAccess         AccessLevel = 0
+	MinimalAccess    AccessLevel = 5
+	GuestAccess      AccessLevel = 10
+	ReporterAccess   AccessLevel = 20
+	DeveloperAccess  AccessLevel = 30
+	MaintainerAccess AccessLevel = 40
+	OwnerAccess      AccessLevel = 50
+)
+
+// String 返回权限级别名称
+func (l AccessLevel) String() string {
+	switch l {
+	case NoAccess:
+		return "no access"
+	case MinimalAccess:
+		return "minimal access"
+	case GuestAccess:
+		return "guest"
+	case ReporterAccess:
+		return "reporter"
+	case DeveloperAccess:
+		return "developer"
+	case MaintainerAccess:
+		return "maintainer"
+	case OwnerAccess:
+		return "owner"
+	}
+	return fmt.Sprintf("AccessLevel(%d)", int(l))
+}
+
+// ProjectMember 仓库成员
+type ProjectMember struct {
+	ID          int         `json:"id"`
+	Username    string      `json:"username"`
+	Name        string      `json:"name"`
+	State       string      `json:"state"`
+	AvatarURL   string      `json:"avatar_url"`
+	WebURL      string      `json:"web_url"`
+	Email       string      `json:"email,omitempty"` // 仅管理员可见
+	AccessLevel AccessLevel `json:"access_level"`
+	CreatedAt   *time.Time  `json:"created_at"`
+	ExpiresAt   *time.Time  `json:"expires_at"`
+}
+
+// UnmarshalJSON 解析gitlab时间, null或空字符串为nil
+func (m *ProjectMember) UnmarshalJSON(data []byte) error {
+	type alias ProjectMember
+	aux := struct {
+		*alias
+		CreatedAt flexTime `json:"created_at"`
+		ExpiresAt flexTime `json:"expires_at"`
+	}{alias: (*alias)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	m.CreatedAt = aux.CreatedAt.t
+	m.ExpiresAt = aux.ExpiresAt.t
+
+	return nil
+}
+
+// ListProjectMembersOptions 获取仓库成员参数
+type ListProjectMembersOptions struct {
+	ListOptions
+	Query   string `url:"query,omitempty"` // 按name、email、username搜索
+	UserIDs []int  `url:"user_ids[],omitempty"`
+}
+
+// ListProjectMembers 获取仓库的直接成员
+func (c *Client) ListProjectMembers(projectID interface{}) ([]ProjectMember, error) {
+	return c.ListProjectMembersWithContext(context.Background(), projectID, nil)
+}
+
+// ListProjectMembersWithContext 获取仓库的直接成员, opt可以为nil, 请求绑定ctx
+func (c *Client) ListProjectMembersWithContext(ctx context.Context, projectID interface{}, opt *ListProjectMembersOptions) ([]ProjectMember, error) {
+	return c.listProjectMembers(ctx, projectID, "/members", opt)
+}
+
+// ListAllProjectMembers 获取仓库成员, 包括从上级组继承和通过共享获得权限的成员
+func (c *Client) ListAllProjectMembers(projectID interface{}) ([]ProjectMember, error) {
+	return c.ListAllProjectMembersWithContext(context.Background(), projectID, nil)
+}
+
+// ListAllProjectMembersWithContext 获取仓库成员, 包括继承的成员, opt可以为nil, 请求绑定ctx
+func (c *Client) ListAllProjectMembersWithContext(ctx context.Context, projectID interface{}, opt *ListProjectMembersOptions) ([]ProjectMember, error) {
+	return c.listProjectMembers(ctx, projectID, "/members/all", opt)
+}
+
+func (c *Client) listProjectMembers(ctx context.Context, projectID interface{}, path string, opt *ListProjectMembersOptions) ([]ProjectMember, error) {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	api, err := addQuery(fmt.Sprintf("/projects/%s%s", pid, path), opt)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []ProjectMember
+	err = c.GetResourceListWithContext(ctx, api, &members)
+	if err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// GetProjectMember 获取仓库的直接成员
+func (c *Client) GetProjectMember(projectID interface{}, userID int) (ProjectMember, error) {
+	return c.GetProjectMemberWithContext(context.Background(), projectID, userID)
+}
+
+// GetProjectMemberWithContext 获取仓库的直接成员, 请求绑定ctx
+func (c *Client) GetProjectMemberWithContext(ctx context.Context, projectID interface{}, userID int) (ProjectMember, error) {
+	var member ProjectMember
+	pid, err := pathID(projectID)
+	if err != nil {
+		return member, err
+	}
+
+	err = c.GetResourceWithContext(ctx, fmt.Sprintf("/projects/%s/members/%d", pid, userID), &member)
+	if err != nil {
+		return member, err
+	}
+
+	return member, nil
+}
+
+// AddProjectMemberOptions 增加仓库成员参数, UserID和Username设置一个即可
+type AddProjectMemberOptions struct {
+	UserID      int         `json:"user_id,omitempty"`
+	Username    string      `json:"username,omitempty"`
+	AccessLevel AccessLevel `json:"access_level"`
+	ExpiresAt   *Date       `json:"expires_at,omitempty"`
+}
+
+// AddProjectMember 增加仓库成员
+func (c *Client) AddProjectMember(projectID interface{}, opt *AddProjectMemberOptions) (ProjectMember, error) {
+	return c.AddProjectMemberWithContext(context.Background(), projectID, opt)
+}
+
+// AddProjectMemberWithContext 增加仓库成员, 请求绑定ctx
+func (c *Client) AddProjectMemberWithContext(ctx context.Context, projectID interface{}, opt *AddProjectMemberOptions) (ProjectMember, error) {
+	var member ProjectMember
+	pid, err := pathID(projectID)
+	if err != nil {
+		return member, err
+	}
+
+	err = c.SendResourceWithContext(ctx, "POST", fmt.Sprintf("/projects/%s/members", pid), opt, &member)
+	if err != nil {
+		return member, err
+	}
+
+	return member, nil
+}
+
+// EditProjectMemberOptions 修改仓库成员参数
+type EditProjectMemberOptions struct {
+	AccessLevel AccessLevel `json:"access_level"`
+	ExpiresAt   *Date       `json:"expires_at,omitempty"`
+}
+
+// EditProjectMember 修改仓库成员的权限和过期时间
+func (c *Client) EditProjectMember(projectID interface{}, userID int, opt *EditProjectMemberOptions) (ProjectMember, error) {
+	return c.EditProjectMemberWithContext(context.Background(), projectID, userID, opt)
+}
+
+// EditProjectMemberWithContext 修改仓库成员的权限和过期时间, 请求绑定ctx
+func (c *Client) EditProjectMemberWithContext(ctx context.Context, projectID interface{}, userID int, opt *EditProjectMemberOptions) (ProjectMember, error) {
+	var member ProjectMember
+	pid, err := pathID(projectID)
+	if err != nil {
+		return member, err
+	}
+
+	err = c.UpdateResourceWithContext(ctx, fmt.Sprintf("/projects/%s/members/%d", pid, userID), opt, &member)
+	if err != nil {
+		return member, err
+	}
+
+	return member, nil
+}
+
+// RemoveProjectMember 移除仓库成员
+func (c *Client) RemoveProjectMember(projectID interface{}, userID int) error {
+	return c.RemoveProjectMemberWithContext(context.Background(), projectID, userID)
+}
+
+// RemoveProjectMemberWithContext 移除仓库成员, 请求绑定ctx
+func (c *Client) RemoveProjectMemberWithContext(ctx context.Context, projectID interface{}, userID int) error {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return err
+	}
+
+	return c.DeleteResourceWithContext(ctx, fmt.Sprintf("/projects/%s/members/%d", pid, userID))
+}
+
+// ShareProjectOptions 共享仓库给组的参数
+type ShareProjectOptions struct {
+	GroupID     int         `json:"group_id"`
+	GroupAccess AccessLevel `json:"group_access"`
+	ExpiresAt   *Date       `json:"expires_at,omitempty"`
+}
+
+// ShareProjectWithGroup 共享仓库给组, 组成员按GroupAccess获得仓库权限
+func (c *Client) ShareProjectWithGroup(projectID interface{}, opt *ShareProjectOptions) error {
+	return c.ShareProjectWithGroupWithContext(context.Background(), projectID, opt)
+}
+
+// ShareProjectWithGroupWithContext 共享仓库给组, 请求绑定ctx
+func (c *Client) ShareProjectWithGroupWithContext(ctx context.Context, projectID interface{}, opt *ShareProjectOptions) error {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return err
+	}
+
+	return c.SendResourceWithContext(ctx, "POST", fmt.Sprintf("/projects/%s/share", pid), opt, nil)
+}
+
+// UnshareProjectWithGroup 取消共享仓库给组
+func (c *Client) UnshareProjectWithGroup(projectID interface{}, groupID int) error {
+	return c.UnshareProjectWithGroupWithContext(context.Background(), projectID, groupID)
+}
+
+// UnshareProjectWithGroupWithContext 取消共享仓库给组, 请求绑定ctx
+func (c *Client) UnshareProjectWithGroupWithContext(ctx context.Context, projectID interface{}, groupID int) error {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return err
+	}
+
+	return c.DeleteResourceWithContext(ctx, fmt.Sprintf("/projects/%s/share/%d", pid, groupID))
+}
+
+// InviteProjectMembersOptions 通过邮箱邀请仓库成员的参数
+type InviteProjectMembersOptions struct {
+	Email       string      `json:"email"` // 多个邮箱用逗号分隔
+	AccessLevel AccessLevel `json:"access_level"`
+	ExpiresAt   *Date       `json:"expires_at,omitempty"`
+}
+
+// InvitationError 邀请部分或全部失败, Messages的key为邮箱
+type InvitationError struct {
+	Messages map[string]string
+}
+
+func (e *InvitationError) Error() string {
+	emails := make([]string, 0, len(e.Messages))
+	for email := range e.Messages {
+		emails = append(emails, email)
+	}
+	sort.Strings(emails)
+
+	msgs := make([]string, 0, len(emails))
+	for _, email := range emails {
+		msgs = append(msgs, email+": "+e.Messages[email])
+	}
+
+	return "gitlab: invitation failed: " + strings.Join(msgs, "; ")
+}
+
+// InviteProjectMembers 通过邮箱邀请仓库成员, 有邮箱邀请失败时返回*InvitationError
+func (c *Client) InviteProjectMembers(projectID interface{}, opt *InviteProjectMembersOptions) error {
+	return c.InviteProjectMembersWithContext(context.Background(), projectID, opt)
+}
+
+// InviteProjectMembersWithContext 通过邮箱邀请仓库成员, 请求绑定ctx
+func (c *Client) InviteProjectMembersWithContext(ctx context.Context, projectID interface{}, opt *InviteProjectMembersOptions) error {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return err
+	}
+
+	// 邀请失败时gitlab仍然返回201, 错误信息在响应体中
+	var result struct {
+		Status  string          `json:"status"`
+		Message json.RawMessage `json:"message"`
+	}
+	err = c.SendResourceWithContext(ctx, "POST", fmt.Sprintf("/projects/%s/invitations", pid), opt, &result)
+	if err != nil || result.Status != "error" {
+		return err
+	}
+
+	// message为邮箱到错误信息的对象, 或者所有邮箱共用的错误信息
+	e := &InvitationError{}
+	if err := json.Unmarshal(result.Message, &e.Messages); err != nil {
+		var msg string
+		if err := json.Unmarshal(result.Message, &msg); err != nil {
+			return fmt.Errorf("gitlab: decode invitation error %s: %v", result.Message, err)
+		}
+		e.Messages = map[string]string{opt.Email: msg}
+	}
+
+	return e
+}
@@ -0,0 +1,149 @@
+package gitlab_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/260by/gitlab"
+	"github.com/260by/gitlab/gitlabtest"
+)
+
+func TestMemberOptionsExpiresAt(t *testing.T) {
+	expires := time.Date(2026, 12, 31, 23, 0, 0, 0, time.FixedZone("CST", 8*3600))
+
+	tests := []struct {
+		name string
+		opt  interface{}
+		want string
+	}{
+		{"add", &gitlab.AddProjectMemberOptions{UserID: 1, AccessLevel: gitlab.DeveloperAccess, ExpiresAt: gitlab.NewDate(expires)}, `{"user_id":1,"access_level":30,"expires_at":"2026-12-31"}`},
+		{"add without expiry", gitlab.AddProjectMemberOptions{UserID: 1, AccessLevel: gitlab.DeveloperAccess}, `{"user_id":1,"access_level":30}`},
+		{"edit", &gitlab.EditProjectMemberOptions{AccessLevel: gitlab.ReporterAccess, ExpiresAt: gitlab.NewDate(expires)}, `{"access_level":20,"expires_at":"2026-12-31"}`},
+		{"share", &gitlab.ShareProjectOptions{GroupID: 2, GroupAccess: gitlab.GuestAccess, ExpiresAt: gitlab.NewDate(expires)}, `{"group_id":2,"group_access":10,"expires_at":"2026-12-31"}`},
+		{"invite", &gitlab.InviteProjectMembersOptions{Email: "a@example.com", AccessLevel: gitlab.GuestAccess, ExpiresAt: gitlab.NewDate(expires)}, `{"email":"a@example.com","access_level":10,"expires_at":"2026-12-31"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.want {
+				t.Errorf("json = %s, want %s", b, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateUnmarshal(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{`"2026-12-31"`, "2026-12-31", false},
+		{`"2026-12-31T10:00:00Z"`, "", true},
+		{`""`, "", true},
+		{`20261231`, "", true},
+	}
+
+	for _, tt := range tests {
+		var d gitlab.Date
+		err := json.Unmarshal([]byte(tt.data), &d)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) = %s, want error", tt.data, d)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.data, err)
+		} else if d.String() != tt.want {
+			t.Errorf("Unmarshal(%s) = %s, want %s", tt.data, d, tt.want)
+		}
+	}
+
+	var opt gitlab.EditProjectMemberOptions
+	if err := json.Unmarshal([]byte(`{"access_level":30,"expires_at":null}`), &opt); err != nil || opt.ExpiresAt != nil {
+		t.Errorf("null expires_at = %v, %v", opt.ExpiresAt, err)
+	}
+}
+
+func TestAddProjectMemberExpiresAt(t *testing.T) {
+	srv := gitlabtest.NewServer()
+	defer srv.Close()
+	c, err := srv.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := srv.AddProject(gitlab.Project{Name: "demo"})
+
+	expires := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	member, err := c.AddProjectMember(p.ID, &gitlab.AddProjectMemberOptions{Username: "alice", AccessLevel: gitlab.DeveloperAccess, ExpiresAt: gitlab.NewDate(expires)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.ExpiresAt == nil || !member.ExpiresAt.Equal(expires) {
+		t.Errorf("member expires at = %v, want %v", member.ExpiresAt, expires)
+	}
+
+	if err := c.ShareProjectWithGroup(p.ID, &gitlab.ShareProjectOptions{GroupID: srv.AddGroup(gitlab.Group{Name: "team"}).ID, GroupAccess: gitlab.ReporterAccess, ExpiresAt: gitlab.NewDate(expires)}); err != nil {
+		t.Fatal(err)
+	}
+	if shares := srv.Shares(p.ID); len(shares) != 1 || shares[0].ExpiresAt == nil || !shares[0].ExpiresAt.Time().Equal(expires) {
+		t.Errorf("shares = %+v", shares)
+	}
+}
+
+func TestInviteProjectMembersError(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"per email", `{"status":"error","message":{"a@example.com":"already invited"}}`, map[string]string{"a@example.com": "already invited"}, false},
+		{"single message", `{"status":"error","message":"Invite limit exceeded"}`, map[string]string{"a@example.com,b@example.com": "Invite limit exceeded"}, false},
+		{"unexpected message", `{"status":"error","message":["a","b"]}`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c, err := gitlab.NewClient(srv.URL, "token")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.InviteProjectMembers(1, &gitlab.InviteProjectMembersOptions{Email: "a@example.com,b@example.com", AccessLevel: gitlab.GuestAccess})
+			var e *gitlab.InvitationError
+			if tt.wantErr {
+				if err == nil || errors.As(err, &e) {
+					t.Fatalf("error = %v, want decode error", err)
+				}
+				return
+			}
+			if !errors.As(err, &e) {
+				t.Fatalf("error = %v, want *InvitationError", err)
+			}
+			if len(e.Messages) != len(tt.want) {
+				t.Fatalf("messages = %v, want %v", e.Messages, tt.want)
+			}
+			for email, msg := range tt.want {
+				if e.Messages[email] != msg {
+					t.Errorf("messages = %v, want %v", e.Messages, tt.want)
+				}
+			}
+		})
+	}
+}
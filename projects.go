@@ -1,29 +1,34 @@
 package gitlab
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
 )
 
 // Project gitlab project info
 type Project struct {
-	ID                int      `json:"id"`
-	Description       string   `json:"description"`
-	Visibility		  string   `json:"visibility"`
-	DefaultBranch     string   `json:"default_branch"`
-	SSHURLToRepo      string   `json:"ssh_url_to_repo"`
-	HTTPURLToRepo     string   `json:"http_url_to_repo"`
-	WebURL            string   `json:"web_url"`
-	ReadmeURL         string   `json:"readme_url"`
-	TagList           []string `json:"tag_list"`
-	Name              string   `json:"name"`
-	NameWithNamespace string   `json:"name_with_namespace"`
-	Path              string   `json:"path"`
-	PathWithNamespace string   `json:"path_with_namespace"`
-	CreatedAt         string   `json:"created_at"`
-	LastActivityAt    string   `json:"last_activity_at"`
-	ForksCount        int      `json:"forks_count"`
-	AvatarURL         string   `json:"avatar_url"`
-	StarCount         int      `json:"star_count"`
+	ID                int        `json:"id"`
+	Description       string     `json:"description"`
+	Visibility        string     `json:"visibility"`
+	DefaultBranch     string     `json:"default_branch"`
+	SSHURLToRepo      string     `json:"ssh_url_to_repo"`
+	HTTPURLToRepo     string     `json:"http_url_to_repo"`
+	WebURL            string     `json:"web_url"`
+	ReadmeURL         string     `json:"readme_url"`
+	TagList           []string   `json:"tag_list"`
+	Name              string     `json:"name"`
+	NameWithNamespace string     `json:"name_with_namespace"`
+	Path              string     `json:"path"`
+	PathWithNamespace string     `json:"path_with_namespace"`
+	CreatedAt         *time.Time `json:"created_at"`
+	LastActivityAt    *time.Time `json:"last_activity_at"`
+	ForksCount        int        `json:"forks_count"`
+	AvatarURL         string     `json:"avatar_url"`
+	StarCount         int        `json:"star_count"`
 	Namespace         struct {
 		ID       int    `json:"id"`
 		Name     string `json:"name"`
@@ -32,14 +37,120 @@ type Project struct {
 		FullPath string `json:"full_path"`
 		ParentID int    `json:"parent_id"`
 	} `json:"namespace"`
-	TriggerToken string `json:"trigger_token"`
-	DeployProjectID int `json:"deploy_project_id"`
+	TriggerToken        string     `json:"trigger_token"`
+	DeployProjectID     int        `json:"deploy_project_id"`
+	Archived            bool       `json:"archived"`
+	Topics              []string   `json:"topics"`
+	MergeMethod         string     `json:"merge_method"`
+	CIConfigPath        string     `json:"ci_config_path"`
+	MarkedForDeletionOn *time.Time `json:"marked_for_deletion_on"` // 开启延迟删除时, 标记为待删除的日期
+	ForkedFromProject   *Project   `json:"forked_from_project,omitempty"`
 }
 
-// CreateProject 新建仓库
+// UnmarshalJSON parse gitlab timestamps, null or empty times are left nil
+func (p *Project) UnmarshalJSON(data []byte) error {
+	type alias Project
+	aux := struct {
+		*alias
+		CreatedAt           flexTime `json:"created_at"`
+		LastActivityAt      flexTime `json:"last_activity_at"`
+		MarkedForDeletionOn flexTime `json:"marked_for_deletion_on"`
+	}{alias: (*alias)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	p.CreatedAt = aux.CreatedAt.t
+	p.LastActivityAt = aux.LastActivityAt.t
+	p.MarkedForDeletionOn = aux.MarkedForDeletionOn.t
+
+	return nil
+}
+
+// CreateProjectOptions 新建仓库参数, Name和Path至少设置一个
+type CreateProjectOptions struct {
+	Name                 string   `json:"name,omitempty"`
+	Path                 string   `json:"path,omitempty"`
+	NamespaceID          int      `json:"namespace_id,omitempty"`
+	Description          string   `json:"description,omitempty"`
+	Visibility           string   `json:"visibility,omitempty"` // private, internal, public
+	DefaultBranch        string   `json:"default_branch,omitempty"`
+	InitializeWithReadme bool     `json:"initialize_with_readme,omitempty"`
+	Topics               []string `json:"topics,omitempty"`
+
+	// 模板和导入, 三者只能使用一种
+	TemplateName                string `json:"template_name,omitempty"` // 内置模板名称, 使用自定义模板时为模板仓库名称
+	UseCustomTemplate           bool   `json:"use_custom_template,omitempty"`
+	TemplateProjectID           int    `json:"template_project_id,omitempty"` // 自定义模板仓库ID
+	GroupWithProjectTemplatesID int    `json:"group_with_project_templates_id,omitempty"`
+	ImportURL                   string `json:"import_url,omitempty"`
+
+	// CI/CD设置
+	CIConfigPath               string `json:"ci_config_path,omitempty"`
+	BuildTimeout               int    `json:"build_timeout,omitempty"`                 // 秒
+	AutoCancelPendingPipelines string `json:"auto_cancel_pending_pipelines,omitempty"` // enabled, disabled
+	SharedRunnersEnabled       *bool  `json:"shared_runners_enabled,omitempty"`
+	AutoDevopsEnabled          *bool  `json:"auto_devops_enabled,omitempty"`
+	PublicJobs                 *bool  `json:"public_jobs,omitempty"`
+
+	// 合并请求设置
+	MergeMethod                               string `json:"merge_method,omitempty"`  // merge, rebase_merge, ff
+	SquashOption                              string `json:"squash_option,omitempty"` // never, always, default_on, default_off
+	OnlyAllowMergeIfPipelineSucceeds          *bool  `json:"only_allow_merge_if_pipeline_succeeds,omitempty"`
+	OnlyAllowMergeIfAllDiscussionsAreResolved *bool  `json:"only_allow_merge_if_all_discussions_are_resolved,omitempty"`
+	RemoveSourceBranchAfterMerge              *bool  `json:"remove_source_branch_after_merge,omitempty"`
+
+	// 功能开关, 取值disabled, private, enabled
+	IssuesAccessLevel            string `json:"issues_access_level,omitempty"`
+	RepositoryAccessLevel        string `json:"repository_access_level,omitempty"`
+	MergeRequestsAccessLevel     string `json:"merge_requests_access_level,omitempty"`
+	BuildsAccessLevel            string `json:"builds_access_level,omitempty"`
+	WikiAccessLevel              string `json:"wiki_access_level,omitempty"`
+	SnippetsAccessLevel          string `json:"snippets_access_level,omitempty"`
+	ContainerRegistryAccessLevel string `json:"container_registry_access_level,omitempty"`
+	LFSEnabled                   *bool  `json:"lfs_enabled,omitempty"`
+	PackagesEnabled              *bool  `json:"packages_enabled,omitempty"`
+
+	ContainerExpirationPolicy *ContainerExpirationPolicyOptions `json:"container_expiration_policy_attributes,omitempty"`
+}
+
+// ContainerExpirationPolicyOptions 容器镜像清理策略
+type ContainerExpirationPolicyOptions struct {
+	Enabled       *bool  `json:"enabled,omitempty"`
+	Cadence       string `json:"cadence,omitempty"` // 1d, 7d, 14d, 1month, 3month
+	KeepN         int    `json:"keep_n,omitempty"`
+	OlderThan     string `json:"older_than,omitempty"` // 7d, 14d, 30d, 90d
+	NameRegex     string `json:"name_regex,omitempty"`
+	NameRegexKeep string `json:"name_regex_keep,omitempty"`
+}
+
+// CreateProject 新建私有仓库
 func (c *Client) CreateProject(projectName string, namespaceID int) (Project, error) {
+	return c.CreateProjectWithContext(context.Background(), projectName, namespaceID)
+}
+
+// CreateProjectWithContext 新建私有仓库, 请求绑定ctx
+func (c *Client) CreateProjectWithContext(ctx context.Context, projectName string, namespaceID int) (Project, error) {
+	return c.CreateProjectWithOptionsWithContext(ctx, &CreateProjectOptions{
+		Name:        projectName,
+		NamespaceID: namespaceID,
+		Visibility:  "private",
+	})
+}
+
+// CreateProjectWithOptions 按opt新建仓库, 未设置Visibility时使用gitlab的默认可见性
+func (c *Client) CreateProjectWithOptions(opt *CreateProjectOptions) (Project, error) {
+	return c.CreateProjectWithOptionsWithContext(context.Background(), opt)
+}
+
+// CreateProjectWithOptionsWithContext 按opt新建仓库, 请求绑定ctx
+func (c *Client) CreateProjectWithOptionsWithContext(ctx context.Context, opt *CreateProjectOptions) (Project, error) {
 	var project Project
-	err := c.CreateResource(fmt.Sprintf("/projects?name=%s&namespace_id=%v&visibility=private", projectName, namespaceID), &project)
+	if opt == nil || (opt.Name == "" && opt.Path == "") {
+		return project, errors.New("gitlab: project name or path is required")
+	}
+
+	err := c.SendResourceWithContext(ctx, "POST", "/projects", opt, &project)
 	if err != nil {
 		return project, err
 	}
@@ -47,11 +158,62 @@ func (c *Client) CreateProject(projectName string, namespaceID int) (Project, er
 	return project, nil
 }
 
+// ListProjectsOptions list projects options, PerPage defaults to 100
+type ListProjectsOptions struct {
+	ListOptions
+	Owned              *bool      `url:"owned,omitempty"`
+	Membership         *bool      `url:"membership,omitempty"`
+	Starred            *bool      `url:"starred,omitempty"`
+	Archived           *bool      `url:"archived,omitempty"`
+	Simple             *bool      `url:"simple,omitempty"`
+	Search             string     `url:"search,omitempty"`
+	Topic              string     `url:"topic,omitempty"`
+	Visibility         string     `url:"visibility,omitempty"` // public, internal, private
+	LastActivityAfter  *time.Time `url:"last_activity_after,omitempty"`
+	LastActivityBefore *time.Time `url:"last_activity_before,omitempty"`
+	OrderBy            string     `url:"order_by,omitempty"` // id, name, path, created_at, updated_at, last_activity_at
+	Sort               string     `url:"sort,omitempty"`     // asc, desc
+}
+
 // ListProjects list all projects
 func (c *Client) ListProjects() ([]Project, error) {
+	return c.ListProjectsWithContext(context.Background(), nil)
+}
+
+// ListProjectsWithContext list projects filtered by opt (may be nil), the requests are bound to ctx
+func (c *Client) ListProjectsWithContext(ctx context.Context, opt *ListProjectsOptions) ([]Project, error) {
+	o := ListProjectsOptions{ListOptions: ListOptions{PerPage: 100}}
+	if opt != nil {
+		o = *opt
+		if o.PerPage == 0 {
+			o.PerPage = 100
+		}
+	}
+
+	api, err := addQuery("/projects", o)
+	if err != nil {
+		return nil, err
+	}
+
 	var projects []Project
+	err = c.GetResourceListWithContext(ctx, api, &projects)
+	if err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// ListProjectsKeyset list all projects using keyset pagination
+func (c *Client) ListProjectsKeyset(opt KeysetOptions) ([]Project, error) {
+	return c.ListProjectsKeysetWithContext(context.Background(), opt)
+}
 
-	err := c.GetResourceList("/projects?per_page=100", &projects)
+// ListProjectsKeysetWithContext list all projects using keyset pagination, the requests are bound to ctx
+func (c *Client) ListProjectsKeysetWithContext(ctx context.Context, opt KeysetOptions) ([]Project, error) {
+	var projects []Project
+
+	err := c.GetResourceListKeysetWithContext(ctx, "/projects", opt, &projects)
 	if err != nil {
 		return nil, err
 	}
@@ -60,9 +222,232 @@ func (c *Client) ListProjects() ([]Project, error) {
 }
 
 // GetProject get single project
-func (c *Client) GetProject(projectID int) (Project, error) {
+func (c *Client) GetProject(projectID interface{}) (Project, error) {
+	return c.GetProjectWithContext(context.Background(), projectID)
+}
+
+// GetProjectWithContext get single project, the request is bound to ctx
+func (c *Client) GetProjectWithContext(ctx context.Context, projectID interface{}) (Project, error) {
 	var project Project
-	err := c.GetResource(fmt.Sprintf("/projects/%v", projectID), &project)
+	pid, err := pathID(projectID)
+	if err != nil {
+		return project, err
+	}
+
+	err = c.GetResourceWithContext(ctx, fmt.Sprintf("/projects/%s", pid), &project)
+	if err != nil {
+		return project, err
+	}
+
+	return project, nil
+}
+
+// EditProjectOptions 修改仓库参数, nil字段保持不变
+type EditProjectOptions struct {
+	Name          *string   `json:"name,omitempty"`
+	Path          *string   `json:"path,omitempty"`
+	Description   *string   `json:"description,omitempty"`
+	Visibility    *string   `json:"visibility,omitempty"` // private, internal, public
+	DefaultBranch *string   `json:"default_branch,omitempty"`
+	MergeMethod   *string   `json:"merge_method,omitempty"` // merge, rebase_merge, ff
+	CIConfigPath  *string   `json:"ci_config_path,omitempty"`
+	Topics        *[]string `json:"topics,omitempty"` // 空切片清空topics
+
+	// 功能开关, 取值disabled, private, enabled
+	IssuesAccessLevel            *string `json:"issues_access_level,omitempty"`
+	RepositoryAccessLevel        *string `json:"repository_access_level,omitempty"`
+	MergeRequestsAccessLevel     *string `json:"merge_requests_access_level,omitempty"`
+	BuildsAccessLevel            *string `json:"builds_access_level,omitempty"`
+	WikiAccessLevel              *string `json:"wiki_access_level,omitempty"`
+	SnippetsAccessLevel          *string `json:"snippets_access_level,omitempty"`
+	ContainerRegistryAccessLevel *string `json:"container_registry_access_level,omitempty"`
+
+	LFSEnabled                                *bool `json:"lfs_enabled,omitempty"`
+	PackagesEnabled                           *bool `json:"packages_enabled,omitempty"`
+	SharedRunnersEnabled                      *bool `json:"shared_runners_enabled,omitempty"`
+	OnlyAllowMergeIfPipelineSucceeds          *bool `json:"only_allow_merge_if_pipeline_succeeds,omitempty"`
+	OnlyAllowMergeIfAllDiscussionsAreResolved *bool `json:"only_allow_merge_if_all_discussions_are_resolved,omitempty"`
+	RemoveSourceBranchAfterMerge              *bool `json:"remove_source_branch_after_merge,omitempty"`
+}
+
+// EditProject 修改仓库设置
+func (c *Client) EditProject(projectID interface{}, opt *EditProjectOptions) (Project, error) {
+	return c.EditProjectWithContext(context.Background(), projectID, opt)
+}
+
+// EditProjectWithContext 修改仓库设置, 请求绑定ctx
+func (c *Client) EditProjectWithContext(ctx context.Context, projectID interface{}, opt *EditProjectOptions) (Project, error) {
+	if opt == nil {
+		opt = &EditProjectOptions{}
+	}
+	return c.sendProject(ctx, "PUT", projectID, "", opt)
+}
+
+// DeleteProjectOptions 删除仓库参数
+type DeleteProjectOptions struct {
+	PermanentlyRemove *bool  `url:"permanently_remove,omitempty"` // 立即删除已标记为待删除的仓库, 需要同时设置FullPath
+	FullPath          string `url:"full_path,omitempty"`
+}
+
+// DeleteProject 删除仓库. gitlab开启延迟删除时仓库只会被标记为待删除, 此时返回的pending为true,
+// 可以在MarkedForDeletionOn之前调用RestoreProject恢复
+func (c *Client) DeleteProject(projectID interface{}) (pending bool, err error) {
+	return c.DeleteProjectWithContext(context.Background(), projectID, nil)
+}
+
+// DeleteProjectWithContext 删除仓库, opt可以为nil, 请求绑定ctx.
+// projectID为路径时先获取数字ID, 标记为待删除的仓库会被gitlab改名, 之后只能通过数字ID查询
+func (c *Client) DeleteProjectWithContext(ctx context.Context, projectID interface{}, opt *DeleteProjectOptions) (pending bool, err error) {
+	id, err := c.projectNumericID(ctx, projectID)
+	if err != nil {
+		return false, err
+	}
+
+	api, err := addQuery(fmt.Sprintf("/projects/%d", id), opt)
+	if err != nil {
+		return false, err
+	}
+
+	err = c.DeleteResourceWithContext(ctx, api)
+	if err != nil || c.dryRun != nil {
+		return false, err
+	}
+
+	// 删除是异步的, 仓库仍然存在且带有删除日期时说明只是被标记为待删除
+	project, err := c.GetProjectWithContext(ctx, id)
+	if err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return project.MarkedForDeletionOn != nil, nil
+}
+
+// projectNumericID 返回仓库的数字ID, projectID为路径时查询仓库
+func (c *Client) projectNumericID(ctx context.Context, projectID interface{}) (int, error) {
+	switch v := projectID.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case string:
+		project, err := c.GetProjectWithContext(ctx, v)
+		if err != nil {
+			return 0, err
+		}
+		return project.ID, nil
+	}
+
+	_, err := pathID(projectID)
+	return 0, err
+}
+
+// RestoreProject 恢复标记为待删除的仓库
+func (c *Client) RestoreProject(projectID interface{}) (Project, error) {
+	return c.RestoreProjectWithContext(context.Background(), projectID)
+}
+
+// RestoreProjectWithContext 恢复标记为待删除的仓库, 请求绑定ctx
+func (c *Client) RestoreProjectWithContext(ctx context.Context, projectID interface{}) (Project, error) {
+	return c.sendProject(ctx, "POST", projectID, "/restore", nil)
+}
+
+// ArchiveProject 归档仓库, 归档后仓库只读
+func (c *Client) ArchiveProject(projectID interface{}) (Project, error) {
+	return c.ArchiveProjectWithContext(context.Background(), projectID)
+}
+
+// ArchiveProjectWithContext 归档仓库, 请求绑定ctx
+func (c *Client) ArchiveProjectWithContext(ctx context.Context, projectID interface{}) (Project, error) {
+	return c.sendProject(ctx, "POST", projectID, "/archive", nil)
+}
+
+// UnarchiveProject 取消归档
+func (c *Client) UnarchiveProject(projectID interface{}) (Project, error) {
+	return c.UnarchiveProjectWithContext(context.Background(), projectID)
+}
+
+// UnarchiveProjectWithContext 取消归档, 请求绑定ctx
+func (c *Client) UnarchiveProjectWithContext(ctx context.Context, projectID interface{}) (Project, error) {
+	return c.sendProject(ctx, "POST", projectID, "/unarchive", nil)
+}
+
+// TransferProject 将仓库转移到其他命名空间, namespace为命名空间ID或完整路径
+func (c *Client) TransferProject(projectID, namespace interface{}) (Project, error) {
+	return c.TransferProjectWithContext(context.Background(), projectID, namespace)
+}
+
+// TransferProjectWithContext 将仓库转移到其他命名空间, 请求绑定ctx
+func (c *Client) TransferProjectWithContext(ctx context.Context, projectID, namespace interface{}) (Project, error) {
+	body := map[string]interface{}{"namespace": namespace}
+	return c.sendProject(ctx, "PUT", projectID, "/transfer", body)
+}
+
+// ForkProjectOptions fork仓库参数, 不指定命名空间时fork到当前用户下
+type ForkProjectOptions struct {
+	NamespaceID   int    `json:"namespace_id,omitempty"`
+	NamespacePath string `json:"namespace_path,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Path          string `json:"path,omitempty"`
+	Description   string `json:"description,omitempty"`
+	Visibility    string `json:"visibility,omitempty"` // private, internal, public
+	Branches      string `json:"branches,omitempty"`   // 只fork指定分支, 多个分支用逗号分隔
+}
+
+// ForkProject fork仓库
+func (c *Client) ForkProject(projectID interface{}, opt *ForkProjectOptions) (Project, error) {
+	return c.ForkProjectWithContext(context.Background(), projectID, opt)
+}
+
+// ForkProjectWithContext fork仓库, opt可以为nil, 请求绑定ctx
+func (c *Client) ForkProjectWithContext(ctx context.Context, projectID interface{}, opt *ForkProjectOptions) (Project, error) {
+	if opt == nil {
+		opt = &ForkProjectOptions{}
+	}
+	return c.sendProject(ctx, "POST", projectID, "/fork", opt)
+}
+
+// StarProject 收藏仓库, 已收藏时直接返回仓库
+func (c *Client) StarProject(projectID interface{}) (Project, error) {
+	return c.StarProjectWithContext(context.Background(), projectID)
+}
+
+// StarProjectWithContext 收藏仓库, 请求绑定ctx
+func (c *Client) StarProjectWithContext(ctx context.Context, projectID interface{}) (Project, error) {
+	return c.toggleStar(ctx, projectID, "/star")
+}
+
+// UnstarProject 取消收藏仓库, 未收藏时直接返回仓库
+func (c *Client) UnstarProject(projectID interface{}) (Project, error) {
+	return c.UnstarProjectWithContext(context.Background(), projectID)
+}
+
+// UnstarProjectWithContext 取消收藏仓库, 请求绑定ctx
+func (c *Client) UnstarProjectWithContext(ctx context.Context, projectID interface{}) (Project, error) {
+	return c.toggleStar(ctx, projectID, "/unstar")
+}
+
+// toggleStar 收藏或取消收藏, 状态未改变时gitlab返回304且没有响应体, 此时重新获取仓库
+func (c *Client) toggleStar(ctx context.Context, projectID interface{}, action string) (Project, error) {
+	project, err := c.sendProject(ctx, "POST", projectID, action, nil)
+	if hasStatus(err, http.StatusNotModified) {
+		return c.GetProjectWithContext(ctx, projectID)
+	}
+
+	return project, err
+}
+
+// sendProject 发送/projects/:id+action请求并解析返回的仓库
+func (c *Client) sendProject(ctx context.Context, method string, projectID interface{}, action string, body interface{}) (Project, error) {
+	var project Project
+	pid, err := pathID(projectID)
+	if err != nil {
+		return project, err
+	}
+
+	err = c.SendResourceWithContext(ctx, method, fmt.Sprintf("/projects/%s%s", pid, action), body, &project)
 	if err != nil {
 		return project, err
 	}
@@ -0,0 +1,56 @@
+package gitlab_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/260by/gitlab"
+)
+
+func TestDeleteProjectByPath(t *testing.T) {
+	// 开启延迟删除时仓库被标记为待删除并改名, 原路径返回404
+	marked := false
+	var deleted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.EscapedPath() {
+		case "GET /api/v4/projects/team%2Fapp":
+			if marked {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"message":"404 Project Not Found"}`))
+				return
+			}
+			w.Write([]byte(`{"id":7,"path_with_namespace":"team/app"}`))
+		case "DELETE /api/v4/projects/7":
+			deleted = r.URL.RawQuery
+			marked = true
+			w.WriteHeader(http.StatusAccepted)
+		case "GET /api/v4/projects/7":
+			w.Write([]byte(`{"id":7,"path_with_namespace":"team/app-deleted-7","marked_for_deletion_on":"2026-10-25"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c, err := gitlab.NewClient(srv.URL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := c.DeleteProject("team/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pending {
+		t.Error("pending = false, want true")
+	}
+	if !marked || deleted != "" {
+		t.Errorf("delete request marked=%v query=%q", marked, deleted)
+	}
+
+	if _, err := c.DeleteProject(3.5); err == nil {
+		t.Error("invalid project ID type returned no error")
+	}
+}
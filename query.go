@@ -0,0 +1,100 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ListOptions 列表接口的通用参数, 页码由分页迭代器控制
+type ListOptions struct {
+	PerPage int `url:"per_page,omitempty"` // 每页数量, 最大100
+}
+
+// Bool 返回v的指针, 用于选项中的*bool字段
+func Bool(v bool) *bool {
+	return &v
+}
+
+// String 返回v的指针, 用于选项中的*string字段
+func String(v string) *string {
+	return &v
+}
+
+// Time 返回t的指针, 用于选项中的*time.Time字段
+func Time(t time.Time) *time.Time {
+	return &t
+}
+
+// addQuery 将opt中带url tag的字段编码后追加到api的查询参数, opt为nil时原样返回.
+// 支持string, bool, int, []string, []int, *bool, *int, *time.Time以及嵌入的结构体, 零值和nil会被忽略
+func addQuery(api string, opt interface{}) (string, error) {
+	v := reflect.ValueOf(opt)
+	if opt == nil || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return api, nil
+	}
+
+	u, err := url.Parse(api)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	if err := encodeQuery(q, reflect.Indirect(v)); err != nil {
+		return "", err
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+func encodeQuery(q url.Values, v reflect.Value) error {
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("gitlab: query options must be a struct, got %s", v.Kind())
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, fv := t.Field(i), v.Field(i)
+
+		if field.Anonymous && fv.Kind() == reflect.Struct {
+			if err := encodeQuery(q, fv); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("url"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		} else if fv.IsZero() {
+			continue
+		}
+
+		switch val := fv.Interface().(type) {
+		case time.Time:
+			q.Set(name, val.UTC().Format(time.RFC3339))
+		case []string:
+			for _, s := range val {
+				q.Add(name, s)
+			}
+		case []int:
+			for _, n := range val {
+				q.Add(name, fmt.Sprint(n))
+			}
+		default:
+			q.Set(name, fmt.Sprint(val))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package gitlab
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter 令牌桶限流器, 可在多个goroutine以及多个Client之间共享
+type RateLimiter struct {
+	mu           sync.Mutex
+	maxRate      float64 // 配置的每秒请求数上限
+	rate         float64 // 当前生效的每秒请求数
+	burst        float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+	adaptive     bool
+}
+
+// NewRateLimiter 创建限流器, rps为每秒请求数, burst为允许的突发请求数
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		maxRate: rps,
+		rate:    rps,
+		burst:   float64(burst),
+		tokens:  float64(burst),
+		last:    time.Now(),
+	}
+}
+
+// NewAdaptiveRateLimiter 创建根据RateLimit-Limit/RateLimit-Remaining/RateLimit-Reset响应头自动调整速率的限流器,
+// rps为速率上限; 剩余配额为0时会等待到RateLimit-Reset再发送请求
+func NewAdaptiveRateLimiter(rps float64, burst int) *RateLimiter {
+	l := NewRateLimiter(rps, burst)
+	l.adaptive = true
+	return l
+}
+
+// WithRateLimit 为Client创建令牌桶限流器, 所有请求(包括重试)都需要先获取令牌
+func WithRateLimit(rps float64, burst int) Option {
+	return WithRateLimiter(NewRateLimiter(rps, burst))
+}
+
+// WithRateLimiter 使用指定的限流器, 多个Client可共享同一个限流器
+func WithRateLimiter(l *RateLimiter) Option {
+	return func(c *Client) error {
+		c.limiter = l
+		return nil
+	}
+}
+
+// Rate 返回当前生效的每秒请求数
+func (l *RateLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// Wait 阻塞直到获取一个令牌或ctx结束
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	wait := l.reserve(time.Now())
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// reserve 取走一个令牌, 返回需要等待的时间
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(now)
+	l.tokens--
+
+	var wait time.Duration
+	if l.tokens < 0 {
+		if l.rate <= 0 {
+			wait = time.Duration(math.MaxInt64)
+		} else {
+			wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+		}
+	}
+	if blocked := l.blockedUntil.Sub(now); blocked > wait {
+		wait = blocked
+	}
+
+	return wait
+}
+
+// advance 按经过的时间补充令牌
+func (l *RateLimiter) advance(now time.Time) {
+	elapsed := now.Sub(l.last)
+	if elapsed <= 0 {
+		return
+	}
+	l.last = now
+	l.tokens = math.Min(l.burst, l.tokens+elapsed.Seconds()*l.rate)
+}
+
+// observe 根据gitlab的限流响应头调整速率, 仅对adaptive限流器生效
+func (l *RateLimiter) observe(h http.Header) {
+	l.observeAt(h, time.Now())
+}
+
+func (l *RateLimiter) observeAt(h http.Header, now time.Time) {
+	if !l.adaptive {
+		return
+	}
+
+	remaining, err := strconv.Atoi(h.Get("RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	window := time.Minute // gitlab的RateLimit-Limit是每分钟的请求数
+	var reset time.Time
+	if ts, err := strconv.ParseInt(h.Get("RateLimit-Reset"), 10, 64); err == nil {
+		reset = time.Unix(ts, 0)
+		if d := reset.Sub(now); d > 0 {
+			window = d
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(now)
+
+	if remaining <= 0 {
+		l.tokens = math.Min(l.tokens, 0)
+		if reset.IsZero() {
+			reset = now.Add(window)
+		}
+		l.blockedUntil = reset
+		return
+	}
+
+	// 按剩余配额在重置前均匀发送, 同时不超过每分钟配额对应的平均速率
+	rate := float64(remaining) / window.Seconds()
+	if limit, err := strconv.Atoi(h.Get("RateLimit-Limit")); err == nil && limit > 0 {
+		rate = math.Min(rate, float64(limit)/time.Minute.Seconds())
+	}
+	l.rate = math.Min(l.maxRate, rate)
+}
@@ -0,0 +1,129 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	l := NewRateLimiter(2, 2)
+	l.last = start
+
+	steps := []struct {
+		at   time.Duration // 相对start的时间
+		want time.Duration
+	}{
+		{0, 0},                      // burst中的第1个令牌
+		{0, 0},                      // burst中的第2个令牌
+		{0, 500 * time.Millisecond}, // 令牌为-1, 按2rps需要等待0.5s
+		{0, time.Second},            // 令牌为-2
+		{2 * time.Second, 0},        // 2s补充4个令牌, 剩余1个(上限为burst)
+		{2 * time.Second, 0},        // 剩余0个
+		{2250 * time.Millisecond, 250 * time.Millisecond}, // 0.25s补充0.5个令牌, 取走后为-0.5
+		{2250 * time.Millisecond, 750 * time.Millisecond}, // 令牌为-1.5
+	}
+
+	for i, s := range steps {
+		if got := l.reserve(start.Add(s.at)); got != s.want {
+			t.Errorf("step %d: reserve = %v, want %v", i, got, s.want)
+		}
+	}
+}
+
+func TestRateLimiterObserve(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	reset := strconv.FormatInt(now.Add(30*time.Second).Unix(), 10)
+
+	tests := []struct {
+		name     string
+		adaptive bool
+		header   http.Header
+		wantRate float64
+		wantWait time.Duration
+	}{
+		{
+			name:     "not adaptive",
+			header:   http.Header{"Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {reset}},
+			wantRate: 10,
+		},
+		{
+			name:     "spread remaining quota until reset",
+			adaptive: true,
+			header:   http.Header{"Ratelimit-Remaining": {"60"}, "Ratelimit-Reset": {reset}},
+			wantRate: 2,
+		},
+		{
+			name:     "limited by per minute quota",
+			adaptive: true,
+			header:   http.Header{"Ratelimit-Remaining": {"270"}, "Ratelimit-Reset": {reset}, "Ratelimit-Limit": {"120"}},
+			wantRate: 2,
+		},
+		{
+			name:     "never above configured rate",
+			adaptive: true,
+			header:   http.Header{"Ratelimit-Remaining": {"6000"}, "Ratelimit-Reset": {reset}},
+			wantRate: 10,
+		},
+		{
+			name:     "quota exhausted blocks until reset",
+			adaptive: true,
+			header:   http.Header{"Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {reset}},
+			wantRate: 10,
+			wantWait: 30 * time.Second,
+		},
+		{
+			name:     "quota exhausted without reset blocks a minute",
+			adaptive: true,
+			header:   http.Header{"Ratelimit-Remaining": {"0"}},
+			wantRate: 10,
+			wantWait: time.Minute,
+		},
+		{
+			name:     "missing headers",
+			adaptive: true,
+			header:   http.Header{},
+			wantRate: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewRateLimiter(10, 5)
+			l.adaptive = tt.adaptive
+			l.last = now
+
+			l.observeAt(tt.header, now)
+			if l.rate != tt.wantRate {
+				t.Errorf("rate = %v, want %v", l.rate, tt.wantRate)
+			}
+			if wait := l.reserve(now); wait != tt.wantWait {
+				t.Errorf("wait = %v, want %v", wait, tt.wantWait)
+			}
+		})
+	}
+}
+
+func TestRateLimiterWaitRefundsOnCancel(t *testing.T) {
+	l := NewRateLimiter(0.001, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Fatalf("Wait = %v, want context.Canceled", err)
+	}
+
+	// 取消的请求归还令牌, 令牌数应回到约0而不是-1
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.01 || tokens > 0.01 {
+		t.Errorf("tokens after cancel = %v, want 0", tokens)
+	}
+}
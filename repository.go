@@ -1,10 +1,9 @@
 package gitlab
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
-	"strings"
+	"net/url"
 )
 
 // File 仓库文件列表信息
@@ -34,9 +33,19 @@ type Action struct {
 }
 
 // GetRepRootList 获取仓库根目录文件和目录列表
-func (c *Client) GetRepRootList(projectID int, branch string) ([]File, error) {
+func (c *Client) GetRepRootList(projectID interface{}, branch string) ([]File, error) {
+	return c.GetRepRootListWithContext(context.Background(), projectID, branch)
+}
+
+// GetRepRootListWithContext 获取仓库根目录文件和目录列表, 请求绑定ctx
+func (c *Client) GetRepRootListWithContext(ctx context.Context, projectID interface{}, branch string) ([]File, error) {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return nil, err
+	}
+
 	var files []File
-	err := c.GetResourceList(fmt.Sprintf("/projects/%v/repository/tree?per_page=100&ref=%s", projectID, branch), &files)
+	err = c.GetResourceListWithContext(ctx, fmt.Sprintf("/projects/%s/repository/tree?%s", pid, url.Values{"per_page": {"100"}, "ref": {branch}}.Encode()), &files)
 	if err != nil {
 		return nil, err
 	}
@@ -45,8 +54,13 @@ func (c *Client) GetRepRootList(projectID int, branch string) ([]File, error) {
 }
 
 // CheckCIFile 检查gitlab仓库根目录文件是否存在
-func (c *Client) CheckCIFile(projectID int, branch string) (bool, error) {
-	files, err := c.GetRepRootList(projectID, branch)
+func (c *Client) CheckCIFile(projectID interface{}, branch string) (bool, error) {
+	return c.CheckCIFileWithContext(context.Background(), projectID, branch)
+}
+
+// CheckCIFileWithContext 检查gitlab仓库根目录文件是否存在, 请求绑定ctx
+func (c *Client) CheckCIFileWithContext(ctx context.Context, projectID interface{}, branch string) (bool, error) {
+	files, err := c.GetRepRootListWithContext(ctx, projectID, branch)
 	if err != nil {
 		return false, err
 	}
@@ -62,9 +76,14 @@ func (c *Client) CheckCIFile(projectID int, branch string) (bool, error) {
 }
 
 // AnalysisRepLanguage 分析存储库语言
-func (c *Client) AnalysisRepLanguage(projectID int, branch string) (string, error) {
+func (c *Client) AnalysisRepLanguage(projectID interface{}, branch string) (string, error) {
+	return c.AnalysisRepLanguageWithContext(context.Background(), projectID, branch)
+}
+
+// AnalysisRepLanguageWithContext 分析存储库语言, 请求绑定ctx
+func (c *Client) AnalysisRepLanguageWithContext(ctx context.Context, projectID interface{}, branch string) (string, error) {
 	var language string
-	files, err := c.GetRepRootList(projectID, branch)
+	files, err := c.GetRepRootListWithContext(ctx, projectID, branch)
 	if err != nil {
 		return language, err
 	}
@@ -90,41 +109,27 @@ func (c *Client) AnalysisRepLanguage(projectID int, branch string) (string, erro
 }
 
 // CreateFile 仓库创建文件,其中files参数为需要创建的文件信息,key:文件路径; value:文件内容
-func (c *Client) CreateFile(projectID int, branch, commitMsg string, files map[string]string) error {
-	client := http.Client{}
+func (c *Client) CreateFile(projectID interface{}, branch, commitMsg string, files map[string]string) error {
+	return c.CreateFileWithContext(context.Background(), projectID, branch, commitMsg, files)
+}
+
+// CreateFileWithContext 仓库创建文件, 请求绑定ctx
+func (c *Client) CreateFileWithContext(ctx context.Context, projectID interface{}, branch, commitMsg string, files map[string]string) error {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return err
+	}
+
 	var actions []Action
 	for k, v := range files {
 		actions = append(actions, Action{Action: "create", FilePath: k, Content: v})
 	}
 
-	url := fmt.Sprintf("%v/api/v4/projects/%v/repository/commits", c.BaseURL, projectID)
 	cf := &CreateFileOptions{
 		Branch:        branch,
 		Actions:       actions,
 		CommitMessage: commitMsg,
 	}
-	reqBody, err := json.Marshal(cf)
-	if err != nil {
-		return err
-	}
-
-	req, err := http.NewRequest("POST", url, strings.NewReader(string(reqBody)))
-	if err != nil {
-		return err
-	}
-
-	req.Header.Add("Private-Token", c.AccessToken)
-	req.Header.Add("Content-Type", "application/json")
-
-	res, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer res.Body.Close()
-
-	if res.StatusCode < 200 || res.StatusCode > 299 {
-		return fmt.Errorf("request error response status code %v", res.StatusCode)
-	}
 
-	return nil
+	return c.SendResourceWithContext(ctx, "POST", fmt.Sprintf("/projects/%s/repository/commits", pid), cf, nil)
 }
@@ -0,0 +1,152 @@
+package gitlab
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// RetryHook 每次重试前调用, attempt为刚失败的第几次请求(从1开始), res和err至少有一个非nil
+type RetryHook func(attempt int, req *http.Request, res *http.Response, err error, wait time.Duration)
+
+// WithMaxRetries 设置失败后的最大重试次数, 0表示不重试, NewClient默认重试3次
+func WithMaxRetries(n int) Option {
+	return func(c *Client) error {
+		if n < 0 {
+			n = 0
+		}
+		c.maxRetries = n
+		return nil
+	}
+}
+
+// WithRetryBackoff 设置重试的指数退避区间, 实际等待时间会加入随机抖动.
+// max同时是Retry-After等响应头指定的等待时间的上限
+func WithRetryBackoff(min, max time.Duration) Option {
+	return func(c *Client) error {
+		c.minBackoff = min
+		c.maxBackoff = max
+		return nil
+	}
+}
+
+// WithRetryHook 设置重试回调, 用于记录或统计重试
+func WithRetryHook(hook RetryHook) Option {
+	return func(c *Client) error {
+		c.retryHook = hook
+		return nil
+	}
+}
+
+// isIdempotent 请求是否可以安全重放
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// shouldRetry 判断是否需要重试:
+// 429所有请求都重试(gitlab未处理该请求); 5xx和连接错误只重试幂等请求.
+// 获取oauth token失败返回的*ErrorResponse已经按同样的规则重试过, 不再重试
+func shouldRetry(req *http.Request, res *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+
+	if res == nil || (err != nil && !isErrorResponse(err)) {
+		return err != nil && !isErrorResponse(err) && isIdempotent(req.Method)
+	}
+
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		return true
+	case res.StatusCode >= 500 && res.StatusCode != http.StatusNotImplemented:
+		return isIdempotent(req.Method)
+	}
+
+	return false
+}
+
+func isErrorResponse(err error) bool {
+	var e *ErrorResponse
+	return errors.As(err, &e)
+}
+
+// backoff 计算第attempt次失败后的等待时间, 优先使用响应头中的等待时间, 但不超过maxBackoff
+func (c *Client) backoff(attempt int, res *http.Response) time.Duration {
+	min, max := c.minBackoff, c.maxBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	if max < min {
+		max = defaultMaxBackoff
+		if max < min {
+			max = min
+		}
+	}
+
+	if res != nil {
+		if wait, ok := retryAfter(res); ok {
+			if wait > max {
+				wait = max
+			}
+			return wait
+		}
+	}
+
+	wait := min
+	for i := 1; i < attempt && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+
+	// 在[wait/2, wait)之间随机, 避免多个客户端同时重试
+	half := int64(wait / 2)
+	if half <= 0 {
+		return wait
+	}
+	return time.Duration(half + rand.Int63n(half))
+}
+
+// retryAfter 解析Retry-After(秒或http日期), 429时还会使用RateLimit-Reset(unix时间戳).
+// gitlab在正常响应中也会返回RateLimit-Reset, 因此其他状态码不使用该响应头
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	if v := res.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return nonNegative(time.Until(t)), true
+		}
+	}
+
+	if v := res.Header.Get("RateLimit-Reset"); v != "" && res.StatusCode == http.StatusTooManyRequests {
+		if ts, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return nonNegative(time.Until(time.Unix(ts, 0))), true
+		}
+	}
+
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,34 @@
+package gitlab
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(40*time.Second).Unix(), 10)
+	c := &Client{minBackoff: 100 * time.Millisecond, maxBackoff: 10 * time.Second}
+
+	tests := []struct {
+		name     string
+		status   int
+		header   http.Header
+		min, max time.Duration
+	}{
+		{"5xx ignores RateLimit-Reset", http.StatusBadGateway, http.Header{"Ratelimit-Reset": {reset}}, 50 * time.Millisecond, 100 * time.Millisecond},
+		{"429 uses RateLimit-Reset capped at max", http.StatusTooManyRequests, http.Header{"Ratelimit-Reset": {reset}}, 10 * time.Second, 10 * time.Second},
+		{"Retry-After seconds", http.StatusServiceUnavailable, http.Header{"Retry-After": {"2"}}, 2 * time.Second, 2 * time.Second},
+		{"Retry-After capped at max", http.StatusTooManyRequests, http.Header{"Retry-After": {"3600"}}, 10 * time.Second, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := &http.Response{StatusCode: tt.status, Header: tt.header}
+			if wait := c.backoff(1, res); wait < tt.min || wait > tt.max {
+				t.Errorf("backoff = %v, want between %v and %v", wait, tt.min, tt.max)
+			}
+		})
+	}
+}
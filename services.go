@@ -0,0 +1,313 @@
+package gitlab
+
+import (
+	"context"
+)
+
+// ProjectsService 仓库相关接口, 各service的projectID/groupID可以是数字ID或完整路径(如team/service-x)
+type ProjectsService interface {
+	List(ctx context.Context, opt *ListProjectsOptions) ([]Project, error)
+	ListKeyset(ctx context.Context, opt KeysetOptions) ([]Project, error)
+	Get(ctx context.Context, projectID interface{}) (Project, error)
+	Create(ctx context.Context, projectName string, namespaceID int) (Project, error)
+	CreateWithOptions(ctx context.Context, opt *CreateProjectOptions) (Project, error)
+	Edit(ctx context.Context, projectID interface{}, opt *EditProjectOptions) (Project, error)
+	Delete(ctx context.Context, projectID interface{}, opt *DeleteProjectOptions) (pending bool, err error)
+	Restore(ctx context.Context, projectID interface{}) (Project, error)
+	Archive(ctx context.Context, projectID interface{}) (Project, error)
+	Unarchive(ctx context.Context, projectID interface{}) (Project, error)
+	Transfer(ctx context.Context, projectID, namespace interface{}) (Project, error)
+	Fork(ctx context.Context, projectID interface{}, opt *ForkProjectOptions) (Project, error)
+	Star(ctx context.Context, projectID interface{}) (Project, error)
+	Unstar(ctx context.Context, projectID interface{}) (Project, error)
+}
+
+// ProjectMembersService 仓库成员相关接口
+type ProjectMembersService interface {
+	List(ctx context.Context, projectID interface{}, opt *ListProjectMembersOptions) ([]ProjectMember, error)
+	ListAll(ctx context.Context, projectID interface{}, opt *ListProjectMembersOptions) ([]ProjectMember, error)
+	Get(ctx context.Context, projectID interface{}, userID int) (ProjectMember, error)
+	Add(ctx context.Context, projectID interface{}, opt *AddProjectMemberOptions) (ProjectMember, error)
+	Edit(ctx context.Context, projectID interface{}, userID int, opt *EditProjectMemberOptions) (ProjectMember, error)
+	Remove(ctx context.Context, projectID interface{}, userID int) error
+	ShareWithGroup(ctx context.Context, projectID interface{}, opt *ShareProjectOptions) error
+	UnshareWithGroup(ctx context.Context, projectID interface{}, groupID int) error
+	Invite(ctx context.Context, projectID interface{}, opt *InviteProjectMembersOptions) error
+}
+
+// GroupsService 组相关接口
+type GroupsService interface {
+	List(ctx context.Context, opt *ListGroupsOptions) ([]Group, error)
+	ListSubGroups(ctx context.Context, groupID interface{}, opt *ListGroupsOptions) ([]Group, error)
+	ListProjects(ctx context.Context, groupID interface{}, opt *ListGroupProjectsOptions) ([]Project, error)
+	Get(ctx context.Context, groupID interface{}) (Group, error)
+	Create(ctx context.Context, opt *CreateGroupOptions) (Group, error)
+	CreateSubGroup(ctx context.Context, name string, parentID int) (Group, error)
+}
+
+// PipelinesService 管道相关接口
+type PipelinesService interface {
+	List(ctx context.Context, projectID interface{}, opt *ListPipelinesOptions) ([]Pipeline, error)
+	Get(ctx context.Context, projectID interface{}, pipelineID int) (Pipeline, error)
+	ListVariables(ctx context.Context, projectID interface{}, pipelineID int) ([]Variable, error)
+}
+
+// JobsService 作业相关接口
+type JobsService interface {
+	ListPipelineJobs(ctx context.Context, projectID interface{}, pipelineID int, opt *ListJobsOptions) ([]Job, error)
+	ListProjectJobs(ctx context.Context, projectID interface{}, opt *ListJobsOptions) ([]Job, error)
+	ListProjectJobsKeyset(ctx context.Context, projectID interface{}) ([]Job, error)
+	Get(ctx context.Context, projectID interface{}, jobID int) (Job, error)
+	Action(ctx context.Context, projectID interface{}, jobID int, action string) (Job, error)
+}
+
+// RepositoryService 仓库文件相关接口
+type RepositoryService interface {
+	ListRootTree(ctx context.Context, projectID interface{}, branch string) ([]File, error)
+	HasCIFile(ctx context.Context, projectID interface{}, branch string) (bool, error)
+	DetectLanguage(ctx context.Context, projectID interface{}, branch string) (string, error)
+	CreateFiles(ctx context.Context, projectID interface{}, branch, commitMsg string, files map[string]string) error
+}
+
+// TriggersService 管道触发器相关接口
+type TriggersService interface {
+	Create(ctx context.Context, projectID interface{}, description string) (Trigger, error)
+	GetToken(ctx context.Context, projectID interface{}) (string, error)
+	TriggerPipeline(ctx context.Context, projectID interface{}, triggerToken string, variables map[string]string) error
+}
+
+// HooksService 仓库webhook相关接口
+type HooksService interface {
+	Add(ctx context.Context, projectID interface{}, webhooks []Webhook) error
+}
+
+var (
+	_ ProjectsService       = (*projectsService)(nil)
+	_ ProjectMembersService = (*projectMembersService)(nil)
+	_ GroupsService         = (*groupsService)(nil)
+	_ PipelinesService      = (*pipelinesService)(nil)
+	_ JobsService           = (*jobsService)(nil)
+	_ RepositoryService     = (*repositoryService)(nil)
+	_ TriggersService       = (*triggersService)(nil)
+	_ HooksService          = (*hooksService)(nil)
+)
+
+// Projects 仓库相关接口
+func (c *Client) Projects() ProjectsService { return &projectsService{c} }
+
+// ProjectMembers 仓库成员相关接口
+func (c *Client) ProjectMembers() ProjectMembersService { return &projectMembersService{c} }
+
+// Groups 组相关接口
+func (c *Client) Groups() GroupsService { return &groupsService{c} }
+
+// Pipelines 管道相关接口
+func (c *Client) Pipelines() PipelinesService { return &pipelinesService{c} }
+
+// Jobs 作业相关接口
+func (c *Client) Jobs() JobsService { return &jobsService{c} }
+
+// Repository 仓库文件相关接口
+func (c *Client) Repository() RepositoryService { return &repositoryService{c} }
+
+// Triggers 管道触发器相关接口
+func (c *Client) Triggers() TriggersService { return &triggersService{c} }
+
+// Hooks 仓库webhook相关接口
+func (c *Client) Hooks() HooksService { return &hooksService{c} }
+
+type projectsService struct{ c *Client }
+
+func (s *projectsService) List(ctx context.Context, opt *ListProjectsOptions) ([]Project, error) {
+	return s.c.ListProjectsWithContext(ctx, opt)
+}
+
+func (s *projectsService) ListKeyset(ctx context.Context, opt KeysetOptions) ([]Project, error) {
+	return s.c.ListProjectsKeysetWithContext(ctx, opt)
+}
+
+func (s *projectsService) Get(ctx context.Context, projectID interface{}) (Project, error) {
+	return s.c.GetProjectWithContext(ctx, projectID)
+}
+
+func (s *projectsService) Create(ctx context.Context, projectName string, namespaceID int) (Project, error) {
+	return s.c.CreateProjectWithContext(ctx, projectName, namespaceID)
+}
+
+func (s *projectsService) CreateWithOptions(ctx context.Context, opt *CreateProjectOptions) (Project, error) {
+	return s.c.CreateProjectWithOptionsWithContext(ctx, opt)
+}
+
+func (s *projectsService) Edit(ctx context.Context, projectID interface{}, opt *EditProjectOptions) (Project, error) {
+	return s.c.EditProjectWithContext(ctx, projectID, opt)
+}
+
+func (s *projectsService) Delete(ctx context.Context, projectID interface{}, opt *DeleteProjectOptions) (bool, error) {
+	return s.c.DeleteProjectWithContext(ctx, projectID, opt)
+}
+
+func (s *projectsService) Restore(ctx context.Context, projectID interface{}) (Project, error) {
+	return s.c.RestoreProjectWithContext(ctx, projectID)
+}
+
+func (s *projectsService) Archive(ctx context.Context, projectID interface{}) (Project, error) {
+	return s.c.ArchiveProjectWithContext(ctx, projectID)
+}
+
+func (s *projectsService) Unarchive(ctx context.Context, projectID interface{}) (Project, error) {
+	return s.c.UnarchiveProjectWithContext(ctx, projectID)
+}
+
+func (s *projectsService) Transfer(ctx context.Context, projectID, namespace interface{}) (Project, error) {
+	return s.c.TransferProjectWithContext(ctx, projectID, namespace)
+}
+
+func (s *projectsService) Fork(ctx context.Context, projectID interface{}, opt *ForkProjectOptions) (Project, error) {
+	return s.c.ForkProjectWithContext(ctx, projectID, opt)
+}
+
+func (s *projectsService) Star(ctx context.Context, projectID interface{}) (Project, error) {
+	return s.c.StarProjectWithContext(ctx, projectID)
+}
+
+func (s *projectsService) Unstar(ctx context.Context, projectID interface{}) (Project, error) {
+	return s.c.UnstarProjectWithContext(ctx, projectID)
+}
+
+type projectMembersService struct{ c *Client }
+
+func (s *projectMembersService) List(ctx context.Context, projectID interface{}, opt *ListProjectMembersOptions) ([]ProjectMember, error) {
+	return s.c.ListProjectMembersWithContext(ctx, projectID, opt)
+}
+
+func (s *projectMembersService) ListAll(ctx context.Context, projectID interface{}, opt *ListProjectMembersOptions) ([]ProjectMember, error) {
+	return s.c.ListAllProjectMembersWithContext(ctx, projectID, opt)
+}
+
+func (s *projectMembersService) Get(ctx context.Context, projectID interface{}, userID int) (ProjectMember, error) {
+	return s.c.GetProjectMemberWithContext(ctx, projectID, userID)
+}
+
+func (s *projectMembersService) Add(ctx context.Context, projectID interface{}, opt *AddProjectMemberOptions) (ProjectMember, error) {
+	return s.c.AddProjectMemberWithContext(ctx, projectID, opt)
+}
+
+func (s *projectMembersService) Edit(ctx context.Context, projectID interface{}, userID int, opt *EditProjectMemberOptions) (ProjectMember, error) {
+	return s.c.EditProjectMemberWithContext(ctx, projectID, userID, opt)
+}
+
+func (s *projectMembersService) Remove(ctx context.Context, projectID interface{}, userID int) error {
+	return s.c.RemoveProjectMemberWithContext(ctx, projectID, userID)
+}
+
+func (s *projectMembersService) ShareWithGroup(ctx context.Context, projectID interface{}, opt *ShareProjectOptions) error {
+	return s.c.ShareProjectWithGroupWithContext(ctx, projectID, opt)
+}
+
+func (s *projectMembersService) UnshareWithGroup(ctx context.Context, projectID interface{}, groupID int) error {
+	return s.c.UnshareProjectWithGroupWithContext(ctx, projectID, groupID)
+}
+
+func (s *projectMembersService) Invite(ctx context.Context, projectID interface{}, opt *InviteProjectMembersOptions) error {
+	return s.c.InviteProjectMembersWithContext(ctx, projectID, opt)
+}
+
+type groupsService struct{ c *Client }
+
+func (s *groupsService) List(ctx context.Context, opt *ListGroupsOptions) ([]Group, error) {
+	return s.c.ListGroupsWithContext(ctx, opt)
+}
+
+func (s *groupsService) ListSubGroups(ctx context.Context, groupID interface{}, opt *ListGroupsOptions) ([]Group, error) {
+	return s.c.ListSubGroupsWithContext(ctx, groupID, opt)
+}
+
+func (s *groupsService) ListProjects(ctx context.Context, groupID interface{}, opt *ListGroupProjectsOptions) ([]Project, error) {
+	return s.c.ListGroupsProjectsWithContext(ctx, groupID, opt)
+}
+
+func (s *groupsService) Get(ctx context.Context, groupID interface{}) (Group, error) {
+	return s.c.GetGroupWithContext(ctx, groupID)
+}
+
+func (s *groupsService) Create(ctx context.Context, opt *CreateGroupOptions) (Group, error) {
+	return s.c.CreateGroupWithContext(ctx, opt)
+}
+
+func (s *groupsService) CreateSubGroup(ctx context.Context, name string, parentID int) (Group, error) {
+	return s.c.CreateSubGroupWithContext(ctx, name, parentID)
+}
+
+type pipelinesService struct{ c *Client }
+
+func (s *pipelinesService) List(ctx context.Context, projectID interface{}, opt *ListPipelinesOptions) ([]Pipeline, error) {
+	return s.c.ListPipelinesWithContext(ctx, projectID, opt)
+}
+
+func (s *pipelinesService) Get(ctx context.Context, projectID interface{}, pipelineID int) (Pipeline, error) {
+	return s.c.GetPipelineWithContext(ctx, projectID, pipelineID)
+}
+
+func (s *pipelinesService) ListVariables(ctx context.Context, projectID interface{}, pipelineID int) ([]Variable, error) {
+	return s.c.ListPipelineVarWithContext(ctx, projectID, pipelineID)
+}
+
+type jobsService struct{ c *Client }
+
+func (s *jobsService) ListPipelineJobs(ctx context.Context, projectID interface{}, pipelineID int, opt *ListJobsOptions) ([]Job, error) {
+	return s.c.ListPipelineJobsWithContext(ctx, projectID, pipelineID, opt)
+}
+
+func (s *jobsService) ListProjectJobs(ctx context.Context, projectID interface{}, opt *ListJobsOptions) ([]Job, error) {
+	return s.c.ListProjectJobsWithContext(ctx, projectID, opt)
+}
+
+func (s *jobsService) ListProjectJobsKeyset(ctx context.Context, projectID interface{}) ([]Job, error) {
+	return s.c.ListProjectJobsKeysetWithContext(ctx, projectID)
+}
+
+func (s *jobsService) Get(ctx context.Context, projectID interface{}, jobID int) (Job, error) {
+	return s.c.GetJobWithContext(ctx, projectID, jobID)
+}
+
+func (s *jobsService) Action(ctx context.Context, projectID interface{}, jobID int, action string) (Job, error) {
+	return s.c.ActionJobWithContext(ctx, projectID, jobID, action)
+}
+
+type repositoryService struct{ c *Client }
+
+func (s *repositoryService) ListRootTree(ctx context.Context, projectID interface{}, branch string) ([]File, error) {
+	return s.c.GetRepRootListWithContext(ctx, projectID, branch)
+}
+
+func (s *repositoryService) HasCIFile(ctx context.Context, projectID interface{}, branch string) (bool, error) {
+	return s.c.CheckCIFileWithContext(ctx, projectID, branch)
+}
+
+func (s *repositoryService) DetectLanguage(ctx context.Context, projectID interface{}, branch string) (string, error) {
+	return s.c.AnalysisRepLanguageWithContext(ctx, projectID, branch)
+}
+
+func (s *repositoryService) CreateFiles(ctx context.Context, projectID interface{}, branch, commitMsg string, files map[string]string) error {
+	return s.c.CreateFileWithContext(ctx, projectID, branch, commitMsg, files)
+}
+
+type triggersService struct{ c *Client }
+
+func (s *triggersService) Create(ctx context.Context, projectID interface{}, description string) (Trigger, error) {
+	return s.c.CreateTriggerWithContext(ctx, projectID, description)
+}
+
+func (s *triggersService) GetToken(ctx context.Context, projectID interface{}) (string, error) {
+	return s.c.GetTriggerWithContext(ctx, projectID)
+}
+
+func (s *triggersService) TriggerPipeline(ctx context.Context, projectID interface{}, triggerToken string, variables map[string]string) error {
+	return s.c.TriggerPipelineWithContext(ctx, projectID, triggerToken, variables)
+}
+
+type hooksService struct{ c *Client }
+
+func (s *hooksService) Add(ctx context.Context, projectID interface{}, webhooks []Webhook) error {
+	return s.c.AddWebhooksWithContext(ctx, projectID, webhooks)
+}
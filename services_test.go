@@ -0,0 +1,25 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServicesOnStructLiteralClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":1,"name":"demo"}`))
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, AccessToken: "token"}
+	project, err := c.Projects().Get(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.Name != "demo" {
+		t.Errorf("project name = %q, want demo", project.Name)
+	}
+}
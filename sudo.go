@@ -0,0 +1,32 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+)
+
+type sudoKey struct{}
+
+// As 返回以username(或用户ID)身份发送请求的Client, 需要管理员token.
+// 返回的Client与c共享http.Client、认证、限流等配置
+func (c *Client) As(username string) *Client {
+	cp := *c
+	cp.sudo = username
+	return &cp
+}
+
+// ContextWithSudo 返回携带sudo用户的ctx, 使用该ctx的单次请求以username身份发送, 优先于Client.As
+func ContextWithSudo(ctx context.Context, username string) context.Context {
+	return context.WithValue(ctx, sudoKey{}, username)
+}
+
+// setSudo 设置Sudo请求头
+func (c *Client) setSudo(req *http.Request) {
+	sudo := c.sudo
+	if s, ok := req.Context().Value(sudoKey{}).(string); ok {
+		sudo = s
+	}
+	if sudo != "" {
+		req.Header.Set("Sudo", sudo)
+	}
+}
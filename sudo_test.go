@@ -0,0 +1,47 @@
+package gitlab_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/260by/gitlab"
+)
+
+func TestSudo(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Sudo")
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer srv.Close()
+
+	c, err := gitlab.NewClient(srv.URL, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		c    *gitlab.Client
+		ctx  context.Context
+		want string
+	}{
+		{"none", c, context.Background(), ""},
+		{"client", c.As("alice"), context.Background(), "alice"},
+		{"context", c, gitlab.ContextWithSudo(context.Background(), "bob"), "bob"},
+		{"context overrides client", c.As("alice"), gitlab.ContextWithSudo(context.Background(), "bob"), "bob"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.c.GetProjectWithContext(tt.ctx, 1); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Sudo header = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// timeLayouts gitlab不同接口返回的时间格式
+var timeLayouts = []string{
+	time.RFC3339Nano,               // 2015-12-24T15:51:21.880Z
+	"2006-01-02T15:04:05.000-0700", // 缺少冒号的时区
+	"2006-01-02 15:04:05 MST",      // 2019-03-15 08:00:00 UTC
+	"2006-01-02 15:04:05 -0700",    // 2019-03-15 08:00:00 +0800
+	"2006-01-02T15:04:05",          // 无时区, 按UTC处理
+	"2006-01-02",                   // 仅日期, 如过期时间
+}
+
+// parseTime 按gitlab可能使用的格式解析时间
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("gitlab: cannot parse time %q", s)
+}
+
+// flexTime 解析gitlab时间字段, null和空字符串为nil
+type flexTime struct {
+	t *time.Time
+}
+
+func (f *flexTime) UnmarshalJSON(data []byte) error {
+	f.t = nil
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+
+	t, err := parseTime(s)
+	if err != nil {
+		return err
+	}
+	f.t = &t
+
+	return nil
+}
+
+// dateLayout gitlab日期参数的格式, 如成员的过期时间
+const dateLayout = "2006-01-02"
+
+// Date 只有日期的参数, 如成员的过期时间, json编码为YYYY-MM-DD, 使用时间所在时区的日期
+type Date time.Time
+
+// NewDate 返回t所在日期
+func NewDate(t time.Time) *Date {
+	d := Date(t)
+	return &d
+}
+
+// Time 返回对应的time.Time
+func (d Date) Time() time.Time {
+	return time.Time(d)
+}
+
+func (d Date) String() string {
+	return time.Time(d).Format(dateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return fmt.Errorf("gitlab: cannot parse date %q", s)
+	}
+	*d = Date(t)
+
+	return nil
+}
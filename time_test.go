@@ -0,0 +1,129 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	cst := time.FixedZone("", 8*3600)
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2015-12-24T15:51:21.880Z", time.Date(2015, 12, 24, 15, 51, 21, 880e6, time.UTC)},
+		{"2015-12-24T23:51:21+08:00", time.Date(2015, 12, 24, 23, 51, 21, 0, cst)},
+		{"2015-12-24T23:51:21.880+0800", time.Date(2015, 12, 24, 23, 51, 21, 880e6, cst)},
+		{"2019-03-15 08:00:00 UTC", time.Date(2019, 3, 15, 8, 0, 0, 0, time.UTC)},
+		{"2019-03-15 08:00:00 +0800", time.Date(2019, 3, 15, 8, 0, 0, 0, cst)},
+		{"2019-03-15T08:00:00", time.Date(2019, 3, 15, 8, 0, 0, 0, time.UTC)},
+		{"2019-03-15", time.Date(2019, 3, 15, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTime(tt.value)
+		if err != nil {
+			t.Errorf("parseTime(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTime(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := parseTime("15/03/2019"); err == nil {
+		t.Error("parseTime(15/03/2019) returned no error")
+	}
+}
+
+func TestFlexTime(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    string // RFC3339, 空表示nil
+		wantErr bool
+	}{
+		{`"2019-03-15 08:00:00 UTC"`, "2019-03-15T08:00:00Z", false},
+		{`null`, "", false},
+		{`""`, "", false},
+		{`"yesterday"`, "", true},
+		{`1552636800`, "", true},
+	}
+
+	for _, tt := range tests {
+		var f flexTime
+		err := json.Unmarshal([]byte(tt.data), &f)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) returned no error", tt.data)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.data, err)
+			continue
+		}
+		got := ""
+		if f.t != nil {
+			got = f.t.Format(time.RFC3339)
+		}
+		if got != tt.want {
+			t.Errorf("Unmarshal(%s) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestJobDurations(t *testing.T) {
+	at := func(s string) *time.Time {
+		v, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &v
+	}
+	recent := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name   string
+		job    Job
+		queued time.Duration
+		wall   time.Duration
+	}{
+		{"finished", Job{CreatedAt: at("2024-01-01T00:00:00Z"), StartedAt: at("2024-01-01T00:00:30Z"), FinishedAt: at("2024-01-01T00:02:30Z")}, 30 * time.Second, 2 * time.Minute},
+		{"no created", Job{StartedAt: at("2024-01-01T00:00:30Z"), FinishedAt: at("2024-01-01T00:01:30Z")}, 0, time.Minute},
+		{"not started", Job{CreatedAt: &recent}, -1, 0},
+		{"running", Job{CreatedAt: at("2024-01-01T00:00:00Z"), StartedAt: &recent}, recent.Sub(*at("2024-01-01T00:00:00Z")), -1},
+	}
+
+	// -1表示截止到当前时间, 至少为1分钟
+	check := func(name string, got, want time.Duration) {
+		if want == -1 {
+			if got < time.Minute || got > time.Hour {
+				t.Errorf("%s = %v, want about 1m", name, got)
+			}
+			return
+		}
+		if got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check("QueuedDuration", tt.job.QueuedDuration(), tt.queued)
+			check("WallTime", tt.job.WallTime(), tt.wall)
+		})
+	}
+
+	var job Job
+	data := `{"id":1,"created_at":"2024-01-01T00:00:00.000Z","started_at":"","finished_at":null}`
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		t.Fatal(err)
+	}
+	if job.CreatedAt == nil || job.StartedAt != nil || job.FinishedAt != nil {
+		t.Errorf("job times = %v %v %v", job.CreatedAt, job.StartedAt, job.FinishedAt)
+	}
+	if job.WallTime() != 0 {
+		t.Errorf("WallTime of unstarted job = %v", job.WallTime())
+	}
+}
@@ -1,10 +1,12 @@
 package gitlab
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 // TriggerPipelineResponse 通过API触发管道响应结构
@@ -25,18 +27,55 @@ type TriggerPipelineResponse struct {
 		AvatarURL string `json:"avatar_url"`
 		WebURL    string `json:"web_url"`
 	} `json:"user"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
-	StartedAt   string `json:"started_at"`
-	FinishedAt  string `json:"finished_at"`
-	CommittedAt string `json:"committed_at"`
-	Duration    int    `json:"duration"`
-	Coverage    bool   `json:"coverage"`
+	CreatedAt   *time.Time `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	StartedAt   *time.Time `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	CommittedAt *time.Time `json:"committed_at"`
+	Duration    int        `json:"duration"`
+	Coverage    bool       `json:"coverage"`
+}
+
+// UnmarshalJSON 解析gitlab时间, null或空字符串为nil
+func (r *TriggerPipelineResponse) UnmarshalJSON(data []byte) error {
+	type alias TriggerPipelineResponse
+	aux := struct {
+		*alias
+		CreatedAt   flexTime `json:"created_at"`
+		UpdatedAt   flexTime `json:"updated_at"`
+		StartedAt   flexTime `json:"started_at"`
+		FinishedAt  flexTime `json:"finished_at"`
+		CommittedAt flexTime `json:"committed_at"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	r.CreatedAt = aux.CreatedAt.t
+	r.UpdatedAt = aux.UpdatedAt.t
+	r.StartedAt = aux.StartedAt.t
+	r.FinishedAt = aux.FinishedAt.t
+	r.CommittedAt = aux.CommittedAt.t
+
+	return nil
 }
 
 // TriggerPipeline 通过API触发管道, projectID为触发项目ID
-func (c *Client) TriggerPipeline(projectID int, triggerToken string, variables map[string]string) error {
-	client := http.Client{}
+func (c *Client) TriggerPipeline(projectID interface{}, triggerToken string, variables map[string]string) error {
+	return c.TriggerPipelineWithContext(context.Background(), projectID, triggerToken, variables)
+}
+
+// TriggerPipelineWithContext 通过API触发管道, 请求绑定ctx
+func (c *Client) TriggerPipelineWithContext(ctx context.Context, projectID interface{}, triggerToken string, variables map[string]string) error {
+	pid, err := pathID(projectID)
+	if err != nil {
+		return err
+	}
+
+	// 在CI中使用job token认证时, 可以直接使用CI_JOB_TOKEN触发管道
+	if jobToken, ok := c.auth.(JobToken); ok && triggerToken == "" {
+		triggerToken = string(jobToken)
+	}
 
 	// 使用form提交数据
 	data := url.Values{}
@@ -47,23 +86,16 @@ func (c *Client) TriggerPipeline(projectID int, triggerToken string, variables m
 		data.Set(fmt.Sprintf("variables[%s]", k), v)
 	}
 
-	url := fmt.Sprintf("%s/api/v4/projects/%v/trigger/pipeline", strings.TrimSuffix(c.BaseURL, "/"), projectID)
-	req, err := http.NewRequest("POST", url, strings.NewReader(data.Encode()))
+	// 触发器使用token参数认证, 不发送认证请求头
+	req, err := c.newRequest(withoutAuth(ctx), "POST", c.endpoint(fmt.Sprintf("/projects/%s/trigger/pipeline", pid)), strings.NewReader(data.Encode()))
 	if err != nil {
 		return err
 	}
-
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	res, err := client.Do(req)
-	if err != nil {
+	if _, err := c.Do(req, nil); err != nil {
 		return err
 	}
-	defer res.Body.Close()
-
-	if res.StatusCode < 200 || res.StatusCode > 299 {
-		return fmt.Errorf("request error response status code %v", res.StatusCode)
-	}
 
 	return nil
 }